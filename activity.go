@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Mentions listed by /activity
+const maxMentions = 30
+
+type mentionsMsg struct {
+	mentions []Mention
+	err      error
+	show     bool // open the activity view once loaded
+}
+
+func fetchMentions(client *SlackClient, show bool) tea.Cmd {
+	return func() tea.Msg {
+		mentions, err := client.Mentions(maxMentions)
+		if err != nil {
+			return mentionsMsg{err: err, show: show}
+		}
+
+		// Mentioning ourselves isn't activity
+		selfID, _ := client.CurrentUserID()
+		others := make([]Mention, 0, len(mentions))
+		for _, mention := range mentions {
+			if !isFromSelf(mention.Message, selfID) {
+				others = append(others, mention)
+			}
+		}
+		return mentionsMsg{others, nil, show}
+	}
+}
+
+// activityCommand handles /activity, which lists our recent mentions
+// across channels, and "/activity <n>", which jumps to the nth of them.
+func (m *model) activityCommand(args string) tea.Cmd {
+	if args == "" {
+		if m.mentions != nil {
+			m.openOverlay("Activity", m.renderActivity())
+		} else {
+			m.setStatus("loading mentions…")
+		}
+		return fetchMentions(m.client, true)
+	}
+
+	n, err := strconv.Atoi(args)
+	if err != nil || n < 1 || n > len(m.mentions) {
+		m.setStatus(fmt.Sprintf("usage: /activity [1-%d]", len(m.mentions)))
+		return nil
+	}
+
+	mention := m.mentions[n-1]
+	m.pendingJump = mention.Ts
+	if mention.Channel.ID == m.channelID {
+		return m.resolvePendingJump()
+	}
+	return joinChannel(m.client, mention.Channel.ID)
+}
+
+// renderActivity lists the mentions, numbered for "/activity <n>".
+func (m *model) renderActivity() string {
+	if len(m.mentions) == 0 {
+		return "No recent mentions."
+	}
+
+	var out strings.Builder
+	for i, mention := range m.mentions {
+		username, err := m.client.UsernameForMessage(mention.Message)
+		if err != nil {
+			username = "unknown"
+		}
+
+		out.WriteString(fmt.Sprintf("%s %s %s %s: %s\n",
+			timeStyle.Render(fmt.Sprintf("%2d.", i+1)),
+			channelStyle.Render("#"+mention.Channel.Name),
+			timeStyle.Render(parseTs(mention.Ts).Format("Jan 2 15:04")),
+			usernameStyle.Render(username),
+			m.redact(mention.Text),
+		))
+	}
+	out.WriteString("\n" + statusStyle.Render("/activity <n> jumps to a mention"))
+
+	return out.String()
+}
+
+// resolvePendingJump selects the message /activity is jumping to once its
+// channel is loaded, or shows it in an overlay when it's too old to be.
+func (m *model) resolvePendingJump() tea.Cmd {
+	ts := m.pendingJump
+	m.pendingJump = ""
+
+	for i := range m.messages {
+		if m.messages[i].id == ts && m.visible(i) {
+			if !m.selecting {
+				m.startSelection()
+			}
+			return m.jumpTo(i)
+		}
+	}
+
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		msg, err := client.Message(channelID, ts)
+		return quotedMessageMsg{msg, err}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How long the archived channel stays on screen before going back to the
+// channel picker
+const archivedDelay = 2 * time.Second
+
+type archiveMsg struct {
+	channelID string
+	archived  bool
+	err       error
+}
+
+type leaveArchivedMsg struct{}
+
+// archiveCommand archives the current channel once /archive is run twice
+// in a row, or unarchives it.
+func (m *model) archiveCommand(archive, confirmed bool) tea.Cmd {
+	if archive && !confirmed {
+		m.pendingArchive = true
+		m.setStatus(fmt.Sprintf("run /archive again to archive %s%s", m.channel.Marker(), m.channelName))
+		return nil
+	}
+
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		var err error
+		if archive {
+			err = client.ArchiveChannel(channelID)
+		} else {
+			err = client.UnarchiveChannel(channelID)
+		}
+		return archiveMsg{channelID, archive, err}
+	}
+}
+
+func (m *model) archived(msg archiveMsg) tea.Cmd {
+	if msg.err != nil {
+		if msg.archived {
+			m.logError(fmt.Errorf("could not archive the channel: %w", msg.err))
+		} else {
+			m.logError(fmt.Errorf("could not unarchive the channel: %w", msg.err))
+		}
+		return nil
+	}
+	if msg.channelID != m.channelID {
+		return nil
+	}
+
+	if m.channel == nil {
+		m.channel = &Channel{ID: msg.channelID}
+	}
+	m.channel.IsArchived = msg.archived
+
+	if !msg.archived {
+		m.setStatus("channel unarchived")
+		return nil
+	}
+
+	m.setStatus("channel archived")
+	return tea.Tick(archivedDelay, func(time.Time) tea.Msg {
+		return leaveArchivedMsg{}
+	})
+}
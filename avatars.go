@@ -0,0 +1,54 @@
+package main
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Colors for author badges, picked by hashing the author's name so each
+// author keeps the same color between runs.
+var authorColors = []lipgloss.Color{"39", "41", "129", "166", "169", "178", "33", "203", "72", "141"}
+
+func authorColor(name string) lipgloss.Color {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return authorColors[h.Sum32()%uint32(len(authorColors))]
+}
+
+// initials returns up to two uppercase letters for a display name, from
+// its first two words or, for single words, its first two letters.
+func initials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var out []rune
+	switch {
+	case len(words) == 0:
+		return "??"
+	case len(words) == 1:
+		out = []rune(words[0])
+		if len(out) > 2 {
+			out = out[:2]
+		}
+	default:
+		out = []rune{[]rune(words[0])[0], []rune(words[1])[0]}
+	}
+
+	return strings.ToUpper(string(out))
+}
+
+// avatar renders the badge shown before the first message of each run of
+// messages by the same author.
+func avatar(name string) string {
+	return lipgloss.NewStyle().Foreground(authorColor(name)).Bold(true).
+		Render("[" + initials(name) + "]")
+}
+
+// avatarPadding keeps grouped messages aligned with the badge above.
+func avatarPadding(name string) string {
+	return strings.Repeat(" ", lipgloss.Width(avatar(name)))
+}
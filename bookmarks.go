@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type bookmarksMsg struct {
+	channelID string
+	bookmarks []Bookmark
+	err       error
+}
+
+type openURLMsg struct {
+	err error
+}
+
+func fetchBookmarks(client *SlackClient, channelID string) tea.Cmd {
+	return func() tea.Msg {
+		bookmarks, err := client.ListBookmarks(channelID)
+		return bookmarksMsg{channelID, bookmarks, err}
+	}
+}
+
+// bookmarksCommand handles /bookmarks, which lists the links bookmarked
+// in the channel, and "/bookmarks <n>", which opens the nth of them.
+func (m *model) bookmarksCommand(args string) tea.Cmd {
+	if args == "" {
+		m.setStatus("loading bookmarks…")
+		return fetchBookmarks(m.client, m.channelID)
+	}
+
+	n, err := strconv.Atoi(args)
+	if err != nil || n < 1 || n > len(m.bookmarks) {
+		if len(m.bookmarks) == 0 {
+			m.setStatus("no bookmarks loaded, list them with /bookmarks first")
+		} else {
+			m.setStatus(fmt.Sprintf("usage: /bookmarks [1-%d]", len(m.bookmarks)))
+		}
+		return nil
+	}
+
+	link := m.bookmarks[n-1].Link
+	m.setStatus("opening " + link)
+	return openURL(link)
+}
+
+func renderBookmarks(bookmarks []Bookmark) string {
+	if len(bookmarks) == 0 {
+		return "This channel has no bookmarks."
+	}
+
+	var out strings.Builder
+	for i, bookmark := range bookmarks {
+		title := bookmark.Title
+		if bookmark.Emoji != "" {
+			title = bookmark.Emoji + " " + title
+		}
+
+		out.WriteString(fmt.Sprintf("%s %s %s\n",
+			timeStyle.Render(fmt.Sprintf("%2d.", i+1)),
+			usernameStyle.Render(title),
+			bookmark.Link,
+		))
+	}
+	out.WriteString("\n" + statusStyle.Render("/bookmarks <n> opens a bookmark in the browser"))
+
+	return out.String()
+}
+
+// openURL opens a link in the default browser.
+func openURL(url string) tea.Cmd {
+	return func() tea.Msg {
+		opener := "xdg-open"
+		if runtime.GOOS == "darwin" {
+			opener = "open"
+		}
+
+		if err := exec.Command(opener, url).Run(); err != nil {
+			return openURLMsg{fmt.Errorf("could not open %s: %w", url, err)}
+		}
+		return openURLMsg{}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// What polling failing with these errors means for the channel we're in
+var channelGoneReasons = map[string]string{
+	"channel_not_found": "this channel was deleted or you can no longer see it",
+	"not_in_channel":    "you were removed from this channel",
+	"is_archived":       "this channel was archived",
+}
+
+type channelInfoMsg struct {
+	channel *Channel
+	err     error
+}
+
+type rejoinMsg struct {
+	channelID string
+	err       error
+}
+
+// channelGone reports polling failing because the channel went away or
+// we're no longer in it, with an explanation of why.
+func channelGone(err error) (string, bool) {
+	var slackErr *SlackError
+	if !errors.As(err, &slackErr) {
+		return "", false
+	}
+
+	reason, ok := channelGoneReasons[slackErr.Code]
+	return reason, ok
+}
+
+// lostChannel explains why polling fails, the first time, and refreshes
+// the channel info in case it was archived or renamed.
+func (m *model) lostChannel(reason string) tea.Cmd {
+	if m.goneReason == reason {
+		return nil
+	}
+	m.goneReason = reason
+	m.setStatus(reason + ", /rejoin to join it again or /switch to pick another channel")
+
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		channel, err := client.ChannelInfo(channelID)
+		return channelInfoMsg{channel, err}
+	}
+}
+
+// updateChannelInfo takes the refreshed info of the current channel,
+// showing its new name if it was renamed.
+func (m *model) updateChannelInfo(msg channelInfoMsg) {
+	// Expected when the channel is gone, which the header already says
+	if msg.err != nil || msg.channel.ID != m.channelID {
+		return
+	}
+
+	if !msg.channel.IsIM && msg.channel.Name != m.channelName {
+		m.setStatus(fmt.Sprintf("channel renamed to %s%s", msg.channel.Marker(), msg.channel.Name))
+		m.channelName = msg.channel.Name
+		m.theme = m.config.channelTheme(m.channelID, m.channelName)
+	}
+	m.channel = msg.channel
+}
+
+func (m *model) rejoin() tea.Cmd {
+	m.setStatus("joining the channel…")
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		return rejoinMsg{channelID, client.JoinChannel(channelID)}
+	}
+}
+
+func (m *model) rejoined(msg rejoinMsg) tea.Cmd {
+	if msg.err != nil {
+		m.logError(fmt.Errorf("could not join the channel: %w", msg.err))
+		return nil
+	}
+	if msg.channelID != m.channelID {
+		return nil
+	}
+
+	m.goneReason = ""
+	m.setStatus("joined the channel again")
+	return fetchMessages(m.client, m.channelID, m.lastFetched, m.config.FetchLimit)
+}
@@ -0,0 +1,42 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Oldest channels are forgotten past this many
+const maxChannelHistory = 20
+
+// visitChannel records a channel switch in the back/forward history,
+// dropping any channels we had gone back from.
+func (m *model) visitChannel(channelID string) {
+	if len(m.channelHistory) > 0 && m.channelHistory[m.channelHistoryIndex] == channelID {
+		return
+	}
+
+	m.channelHistory = append(m.channelHistory[:m.channelHistoryIndex+1], channelID)
+	if len(m.channelHistory) > maxChannelHistory {
+		m.channelHistory = m.channelHistory[len(m.channelHistory)-maxChannelHistory:]
+	}
+	m.channelHistoryIndex = len(m.channelHistory) - 1
+}
+
+// navigateChannels goes back (delta -1) or forward (delta 1) through the
+// visited channels.
+func (m *model) navigateChannels(delta int) tea.Cmd {
+	i := m.channelHistoryIndex + delta
+	if i < 0 || i >= len(m.channelHistory) {
+		if delta < 0 {
+			m.setStatus("no previous channel")
+		} else {
+			m.setStatus("no next channel")
+		}
+		return nil
+	}
+
+	join := joinChannel(m.client, m.channelHistory[i])
+	return func() tea.Msg {
+		msg := join().(channelSwitchMsg)
+		msg.fromHistory = true
+		msg.historyIndex = i
+		return msg
+	}
+}
@@ -29,16 +29,24 @@ SOFTWARE.
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rneatherway/slack"
@@ -53,25 +61,105 @@ type CursorResponseMetadata struct {
 }
 
 type Attachment struct {
-	ID   int
-	Text string
+	ID      int
+	Text    string
+	FromURL string `json:"from_url"` // set when a message link is unfurled
+
+	// Set when a link to a web page is unfurled
+	Title       string
+	TitleLink   string `json:"title_link"`
+	ServiceName string `json:"service_name"`
+	OriginalURL string `json:"original_url"`
+	IsMsgUnfurl bool   `json:"is_msg_unfurl"`
+}
+
+type File struct {
+	ID                 string
+	Name               string
+	Title              string
+	Mimetype           string
+	Filetype           string
+	Mode               string
+	Size               int64
+	Preview            string
+	PreviewIsTruncated bool `json:"preview_is_truncated"`
+}
+
+// IsText reports whether the file is a text snippet or plain text file
+// whose content can be displayed in the terminal.
+func (f File) IsText() bool {
+	return f.Mode == "snippet" || strings.HasPrefix(f.Mimetype, "text/")
+}
+
+type FileInfoResponse struct {
+	Ok          bool
+	File        File
+	Content     string
+	IsTruncated bool `json:"is_truncated"`
+}
+
+type Reaction struct {
+	Name  string
+	Count int
+	Users []string
+}
+
+// UserProfile is the author profile Slack sometimes includes inline with
+// messages.
+type UserProfile struct {
+	Name        string
+	RealName    string `json:"real_name"`
+	DisplayName string `json:"display_name"`
 }
 
 type Message struct {
 	User        string
-	BotID       string `json:"bot_id"`
+	UserProfile *UserProfile `json:"user_profile"`
+	BotID       string       `json:"bot_id"`
+	AppID       string       `json:"app_id"`
+	Username    string       `json:"username"`
+	BotProfile  *BotProfile  `json:"bot_profile"`
 	Text        string
 	Attachments []Attachment
+	Files       []File
+	Reactions   []Reaction
 	Ts          string
+	ThreadTs    string `json:"thread_ts"`
 	Type        string
-	ReplyCount  int `json:"reply_count"`
+	Subtype     string `json:"subtype"`
+	ReplyCount  int    `json:"reply_count"`
+	LatestReply string `json:"latest_reply"`
+
+	// The message as received, for debugging
+	Raw json.RawMessage `json:"-"`
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	// Without the methods, to not recurse
+	type message Message
+	if err := json.Unmarshal(data, (*message)(m)); err != nil {
+		return err
+	}
+
+	m.Raw = append(json.RawMessage(nil), data...)
+	return nil
 }
 
 type SendMessage struct {
+	TS          string       `json:"ts,omitempty"` // message to update, for chat.update
 	ThreadTS    string       `json:"thread_ts,omitempty"`
 	Channel     string       `json:"channel"` // required
 	Text        string       `json:"text,omitempty"`
 	Attachments []Attachment `json:"attachments,omitempty"`
+	Sender
+}
+
+// Sender overrides the name and icon messages are posted with, which
+// Slack only allows for bot tokens.
+type Sender struct {
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"` // e.g. :robot_face:
+	IconURL   string `json:"icon_url,omitempty"`
 }
 
 type SendMessageResponse struct {
@@ -88,6 +176,46 @@ type RTMConnectResponse struct {
 	URL   string `json:"url"`
 }
 
+type AuthTestResponse struct {
+	Ok     bool   `json:"ok"`
+	Error  string `json:"error"`
+	UserID string `json:"user_id"`
+	BotID  string `json:"bot_id"` // only set for bot tokens
+	User   string `json:"user"`
+	TeamID string `json:"team_id"`
+	Team   string `json:"team"`
+}
+
+// SavedItem is a message saved for later, either through the legacy stars
+// API or the newer saved items API.
+type SavedItem struct {
+	ChannelID string
+	Ts        string
+	Message   *Message
+}
+
+type StarsListResponse struct {
+	CursorResponseMetadata
+	Ok    bool
+	Error string
+	Items []struct {
+		Type    string
+		Channel string
+		Message Message
+	}
+}
+
+type SavedListResponse struct {
+	CursorResponseMetadata
+	Ok         bool
+	Error      string
+	SavedItems []struct {
+		ItemID   string `json:"item_id"`
+		ItemType string `json:"item_type"`
+		Ts       string
+	} `json:"saved_items"`
+}
+
 type BotProfile struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -102,15 +230,53 @@ func (r *SendMessageResponse) Output(team, channelID string) string {
 
 type HistoryResponse struct {
 	CursorResponseMetadata
-	Ok       bool
-	HasMore  bool `json:"has_more"`
-	Messages []Message
+	Ok        bool
+	HasMore   bool `json:"has_more"`
+	IsLimited bool `json:"is_limited"` // older messages hidden by the plan's limits
+	Messages  []Message
+}
+
+// Limited reports whether Slack held back older messages, either saying so
+// or claiming there's more while returning nothing.
+func (r *HistoryResponse) Limited() bool {
+	return r.IsLimited || (r.HasMore && len(r.Messages) == 0)
 }
 
 type Channel struct {
 	ID         string
 	Name       string
 	Is_Channel bool
+	IsPrivate  bool `json:"is_private"`
+	IsMember   bool `json:"is_member"`
+	IsIM       bool `json:"is_im"`
+	IsMpIM     bool `json:"is_mpim"`
+	IsShared   bool `json:"is_shared"`
+	IsArchived bool `json:"is_archived"`
+	Topic      struct {
+		Value string
+	}
+	Purpose struct {
+		Value string
+	}
+	NumMembers int    `json:"num_members"` // only from conversations.info
+	User       string `json:"user"`        // the other user in a DM
+}
+
+// Marker returns the symbol shown before the channel name for its type,
+// defaulting to '#' when the type isn't known.
+func (ch *Channel) Marker() string {
+	switch {
+	case ch == nil:
+		return "#"
+	case ch.IsIM:
+		return "@"
+	case ch.IsMpIM:
+		return "👥 "
+	case ch.IsPrivate:
+		return "🔒 "
+	default:
+		return "#"
+	}
 }
 
 type ChannelInfoResponse struct {
@@ -145,6 +311,92 @@ type Cache struct {
 	Users    map[string]string
 }
 
+// Used when a rate limited response doesn't say how long to wait
+const defaultRetryAfter = 30 * time.Second
+
+// Requests on a dead connection, e.g. after a network change, fail after
+// this long instead of hanging
+const requestTimeout = time.Minute
+
+// Defaults for how long connecting to Slack may take, and how often idle
+// connections are probed to tell they're still alive
+const (
+	dialTimeout = 10 * time.Second
+	keepAlive   = 30 * time.Second
+)
+
+// rateLimitTransport records how long Slack asked us to back off when a
+// request gets rate limited, so polling can slow down accordingly. It also
+// keeps the scopes Slack last said the token has.
+type rateLimitTransport struct {
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	until  time.Time
+	scopes string
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The base may be swapped by Reset while requests are in flight
+	t.mu.Lock()
+	base := t.base
+	t.mu.Unlock()
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if scopes := resp.Header.Get("X-OAuth-Scopes"); scopes != "" {
+		t.mu.Lock()
+		t.scopes = scopes
+		t.mu.Unlock()
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+
+	retryAfter := defaultRetryAfter
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+
+	t.mu.Lock()
+	t.until = time.Now().Add(retryAfter)
+	t.mu.Unlock()
+
+	return resp, err
+}
+
+func (t *rateLimitTransport) throttledUntil() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.until
+}
+
+func (t *rateLimitTransport) oauthScopes() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.scopes
+}
+
+// baseURLTransport sends requests to another server, keeping their path.
+type baseURLTransport struct {
+	url  *url.URL
+	base http.RoundTripper
+}
+
+func (t *baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.url.Scheme
+	req.URL.Host = t.url.Host
+	req.URL.Path = strings.TrimSuffix(t.url.Path, "/") + req.URL.Path
+	req.Host = t.url.Host
+
+	return t.base.RoundTrip(req)
+}
+
 type SlackClient struct {
 	cachePath string
 	team      string
@@ -152,6 +404,26 @@ type SlackClient struct {
 	client    *slack.Client
 	log       *log.Logger
 	tz        *time.Location
+	progress  io.Writer
+	userID    string
+	botID     string
+	transport *rateLimitTransport
+
+	// The client requests go through, with the configured timeout
+	httpClient *http.Client
+
+	profiles map[string]*Profile
+	teamInfo *Team
+
+	// Names of the workspace's custom emoji, fetched once
+	customEmoji []string
+
+	// Log routine events, like polls that found nothing, too
+	debugLog bool
+
+	// Lookups that failed, so they aren't retried on every poll
+	usersListFailed bool
+	unresolvedUsers map[string]bool
 }
 
 func NewClient(team string, log *log.Logger) (*SlackClient, error) {
@@ -171,17 +443,31 @@ func NewClient(team string, log *log.Logger) (*SlackClient, error) {
 		return nil, err
 	}
 
+	transport := &rateLimitTransport{base: http.DefaultTransport}
+	httpClient := &http.Client{Transport: transport, Timeout: requestTimeout}
+	client.WithHTTPClient(httpClient)
+
 	c := &SlackClient{
-		cachePath: cachePath,
-		team:      team,
-		client:    client,
-		log:       log,
-		tz:        time.Now().Location(),
+		cachePath:  cachePath,
+		team:       team,
+		client:     client,
+		log:        log,
+		tz:         time.Now().Location(),
+		progress:   os.Stderr,
+		transport:  transport,
+		httpClient: httpClient,
 	}
 
 	return c, c.loadCache()
 }
 
+// debugf logs only with the debug log level.
+func (c *SlackClient) debugf(format string, v ...interface{}) {
+	if c.debugLog {
+		c.log.Printf(format, v...)
+	}
+}
+
 // Null produces a SlackClient suitable for testing that does not try to load
 // the Slack token or cookies from disk, and starts with an empty cache.
 func Null(team string, roundTripper http.RoundTripper) (*SlackClient, error) {
@@ -190,20 +476,120 @@ func Null(team string, roundTripper http.RoundTripper) (*SlackClient, error) {
 		return nil, err
 	}
 
+	transport := &rateLimitTransport{base: roundTripper}
 	client := slack.NewClient("test-team")
-	client.WithHTTPClient(&http.Client{Transport: roundTripper})
+	httpClient := &http.Client{Transport: transport}
+	client.WithHTTPClient(httpClient)
 
 	return &SlackClient{
-		team:      team,
-		client:    client,
-		cachePath: cacheFile.Name(),
-		tz:        time.UTC,
+		team:       team,
+		client:     client,
+		cachePath:  cacheFile.Name(),
+		tz:         time.UTC,
+		progress:   io.Discard,
+		transport:  transport,
+		httpClient: httpClient,
 	}, nil
 }
 
+// WithBaseURL sends API requests to baseURL instead of Slack, e.g. a fake
+// server serving canned responses. It must be called before any request.
+func (c *SlackClient) WithBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q", baseURL)
+	}
+
+	c.transport.mu.Lock()
+	defer c.transport.mu.Unlock()
+
+	c.transport.base = &baseURLTransport{url: u, base: c.transport.base}
+	return nil
+}
+
+// Reset drops the pooled connections, which may be stale after a network
+// change such as joining a VPN, and carries on with a fresh transport.
+func (c *SlackClient) Reset() {
+	c.transport.mu.Lock()
+	defer c.transport.mu.Unlock()
+
+	c.transport.base = freshTransport(c.transport.base)
+}
+
+// SetTimeouts tunes the connection to Slack: how long a request and
+// connecting may take, and how often idle connections are probed. A zero
+// keepAlive turns the probes off.
+func (c *SlackClient) SetTimeouts(request, dial, keepAlive time.Duration) {
+	// net.Dialer takes a negative value to turn them off
+	if keepAlive == 0 {
+		keepAlive = -1
+	}
+
+	c.transport.mu.Lock()
+	c.transport.base = swapTransport(c.transport.base, func(t *http.Transport) *http.Transport {
+		tuned := t.Clone()
+		tuned.DialContext = (&net.Dialer{Timeout: dial, KeepAlive: keepAlive}).DialContext
+		return tuned
+	})
+	c.transport.mu.Unlock()
+
+	c.httpClient = &http.Client{Transport: c.transport, Timeout: request}
+	c.client.WithHTTPClient(c.httpClient)
+}
+
+// freshTransport replaces the http.Transport at the bottom of a chain of
+// round trippers with a new one, closing the old one's connections.
+func freshTransport(rt http.RoundTripper) http.RoundTripper {
+	return swapTransport(rt, func(t *http.Transport) *http.Transport {
+		t.CloseIdleConnections()
+		// Keeps the timeouts, but none of the connections
+		return t.Clone()
+	})
+}
+
+// swapTransport replaces the http.Transport at the bottom of a chain of
+// round trippers with the one swap returns for it.
+func swapTransport(rt http.RoundTripper, swap func(*http.Transport) *http.Transport) http.RoundTripper {
+	switch t := rt.(type) {
+	case *baseURLTransport:
+		return &baseURLTransport{url: t.url, base: swapTransport(t.base, swap)}
+	case *http.Transport:
+		return swap(t)
+	default:
+		// Nothing to swap, e.g. a stub in tests
+		return rt
+	}
+}
+
+// UsernameForMessage returns the name to show as the author of a message.
+// When the user can't be looked up (e.g. the token lacks users:read) it
+// falls back to the names included in the message, or the raw user ID.
 func (c *SlackClient) UsernameForMessage(message Message) (string, error) {
 	if message.User != "" {
-		return c.UsernameForID(message.User)
+		if name, err := c.UsernameForID(message.User); err == nil {
+			return name, nil
+		}
+	}
+
+	if p := message.UserProfile; p != nil {
+		for _, name := range []string{p.DisplayName, p.RealName, p.Name} {
+			if name != "" {
+				return name, nil
+			}
+		}
+	}
+	if message.BotProfile != nil && message.BotProfile.Name != "" {
+		return message.BotProfile.Name, nil
+	}
+	if message.Username != "" {
+		return message.Username, nil
+	}
+
+	if message.User != "" {
+		return message.User, nil
 	}
 	if message.BotID != "" {
 		return fmt.Sprintf("bot %s", message.BotID), nil
@@ -211,6 +597,11 @@ func (c *SlackClient) UsernameForMessage(message Message) (string, error) {
 	return "ghost", nil
 }
 
+// ThrottledUntil returns when the last rate limit imposed by Slack expires.
+func (c *SlackClient) ThrottledUntil() time.Time {
+	return c.transport.throttledUntil()
+}
+
 func (c *SlackClient) API(verb, path string, params map[string]string, body []byte) ([]byte, error) {
 	return c.client.API(context.TODO(), verb, path, params, body)
 }
@@ -228,33 +619,115 @@ func (c *SlackClient) post(path string, params map[string]string, msg *SendMessa
 	return c.API("POST", path, params, messageBytes)
 }
 
+// SlackError is a failure reported by the Slack API in the response body,
+// which comes with a 200 status like any other response.
+type SlackError struct {
+	Method   string
+	Code     string // e.g. not_in_channel
+	Warnings []string
+}
+
+// Explanations for the errors users are likely to run into
+var slackErrorHints = map[string]string{
+	"not_in_channel":         "join the channel first",
+	"channel_not_found":      "the channel doesn't exist or you can't see it",
+	"msg_too_long":           "the message is too long",
+	"no_text":                "the message is empty",
+	"missing_scope":          "the token lacks a required permission",
+	"not_authed":             "not logged in to Slack",
+	"invalid_auth":           "the Slack session expired, log in again",
+	"ratelimited":            "too many requests, try again later",
+	"cant_update_message":    "only your own messages can be edited",
+	"cant_delete_message":    "only your own messages can be deleted",
+	"already_reacted":        "you already reacted with that emoji",
+	"already_in_channel":     "the user is already in the channel",
+	"cant_invite_self":       "you can't invite yourself",
+	"cant_kick_self":         "you can't remove yourself",
+	"cant_kick_from_general": "nobody can be removed from the general channel",
+	"restricted_action":      "the workspace settings don't allow you to do that",
+	"already_archived":       "the channel is already archived",
+	"cant_archive_general":   "the general channel can't be archived",
+	"not_archived":           "the channel isn't archived",
+	"user_not_found":         "the user doesn't exist",
+	"is_archived":            "the channel is archived",
+}
+
+func (e *SlackError) Error() string {
+	msg := fmt.Sprintf("%s failed: %s", e.Method, e.Code)
+	if hint, ok := slackErrorHints[e.Code]; ok {
+		msg += " (" + hint + ")"
+	}
+	if len(e.Warnings) > 0 {
+		msg += ", warnings: " + strings.Join(e.Warnings, ", ")
+	}
+	return msg
+}
+
+// decode checks the ok flag of a Slack API response, returning a
+// *SlackError when it's false, and unmarshals the response into v unless
+// v is nil.
+func decode(method string, body []byte, v any) error {
+	envelope := struct {
+		Ok               bool
+		Error            string
+		ResponseMetadata struct {
+			Warnings []string
+		} `json:"response_metadata"`
+	}{}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("%s returned an invalid response: %w", method, err)
+	}
+
+	if !envelope.Ok {
+		code := envelope.Error
+		if code == "" {
+			code = "unknown_error"
+		}
+		return &SlackError{Method: method, Code: code, Warnings: envelope.ResponseMetadata.Warnings}
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
+
 func (c *SlackClient) ChannelInfo(id string) (*Channel, error) {
 	body, err := c.get("conversations.info",
-		map[string]string{"channel": id})
+		map[string]string{"channel": id, "include_num_members": "true"})
 	if err != nil {
 		return nil, err
 	}
 
 	channelInfoReponse := &ChannelInfoResponse{}
-	err = json.Unmarshal(body, channelInfoReponse)
-	if err != nil {
+	if err := decode("conversations.info", body, channelInfoReponse); err != nil {
 		return nil, err
 	}
 
-	if !channelInfoReponse.Ok {
-		return nil, fmt.Errorf("conversations.info response not OK: %s", body)
+	return &channelInfoReponse.Channel, nil
+}
+
+// ChannelDisplayName returns the name to show for a channel, which for DMs
+// is the name of the other user.
+func (c *SlackClient) ChannelDisplayName(ch *Channel) string {
+	if !ch.IsIM {
+		return ch.Name
 	}
 
-	return &channelInfoReponse.Channel, nil
+	name, err := c.UsernameForID(ch.User)
+	if err != nil {
+		return ch.User
+	}
+	return name
 }
 
 func (c *SlackClient) conversations() ([]Channel, error) {
-	fmt.Fprintf(os.Stderr, "Populating channel cache (this may take a while)...")
+	fmt.Fprintf(c.progress, "Populating channel cache (this may take a while)...")
 
 	channels := make([]Channel, 0, 1000)
 	conversations := &ConversationsResponse{}
 	for {
-		c.log.Printf("Fetching conversations with cursor %q", conversations.ResponseMetadata.NextCursor)
+		c.debugf("Fetching conversations with cursor %q", conversations.ResponseMetadata.NextCursor)
 		body, err := c.get("conversations.list",
 			map[string]string{
 				"cursor":           conversations.ResponseMetadata.NextCursor,
@@ -269,23 +742,57 @@ func (c *SlackClient) conversations() ([]Channel, error) {
 			return nil, err
 		}
 
-		if err = json.Unmarshal(body, conversations); err != nil {
+		if err = decode("conversations.list", body, conversations); err != nil {
 			return nil, err
 		}
 
-		if !conversations.Ok {
-			return nil, fmt.Errorf("conversations response not OK: %s", body)
-		}
-
 		channels = append(channels, conversations.Channels...)
-		fmt.Fprintf(os.Stderr, "%d...", len(channels))
+		fmt.Fprintf(c.progress, "%d...", len(channels))
 
 		if conversations.ResponseMetadata.NextCursor == "" {
 			break
 		}
 	}
 
-	fmt.Fprintf(os.Stderr, "done!\n")
+	fmt.Fprintf(c.progress, "done!\n")
+	return channels, nil
+}
+
+// ListChannels returns the channels and group DMs we're a member of.
+func (c *SlackClient) ListChannels() ([]Channel, error) {
+	return c.memberConversations("public_channel,private_channel,mpim")
+}
+
+// ListDMs returns our direct message conversations.
+func (c *SlackClient) ListDMs() ([]Channel, error) {
+	return c.memberConversations("im")
+}
+
+func (c *SlackClient) memberConversations(types string) ([]Channel, error) {
+	var channels []Channel
+	resp := &ConversationsResponse{}
+	for {
+		body, err := c.get("users.conversations", map[string]string{
+			"cursor":           resp.ResponseMetadata.NextCursor,
+			"exclude_archived": "true",
+			"limit":            "1000",
+			"types":            types,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := decode("users.conversations", body, resp); err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, resp.Channels...)
+
+		if resp.ResponseMetadata.NextCursor == "" {
+			break
+		}
+	}
+
 	return channels, nil
 }
 
@@ -301,15 +808,10 @@ func (c *SlackClient) users() ([]User, error) {
 			return nil, err
 		}
 
-		err = json.Unmarshal(body, resp)
-		if err != nil {
+		if err := decode("users.list", body, resp); err != nil {
 			return nil, err
 		}
 
-		if !resp.Ok {
-			return nil, fmt.Errorf("users response not OK: %s", body)
-		}
-
 		users = append(users, resp.Members...)
 
 		if resp.ResponseMetadata.NextCursor == "" {
@@ -332,67 +834,147 @@ func (c *SlackClient) loadCache() error {
 }
 
 func (c *SlackClient) History(channelID string, startTimestamp string, thread string, limit int) (*HistoryResponse, error) {
+	if thread != "" {
+		return c.threadHistory(channelID, startTimestamp, thread, limit)
+	}
+
+	// Catching up since a message pages through all the newer ones, so a
+	// burst of more than limit messages between polls isn't cut short
 	params := map[string]string{
 		"channel":   channelID,
-		"ts":        startTimestamp,
+		"oldest":    startTimestamp,
 		"inclusive": "true",
 		"limit":     strconv.Itoa(limit),
 	}
+	var messages []Message
+	for {
+		body, err := c.get("conversations.history", params)
+		if err != nil {
+			return nil, err
+		}
 
-	if thread != "" {
-		params["ts"] = thread
-		params["oldest"] = startTimestamp
+		historyResponse := &HistoryResponse{}
+		if err := decode("conversations.history", body, historyResponse); err != nil {
+			return nil, err
+		}
+		c.debugf("%s", body)
+		c.debugf("%#v", historyResponse)
+
+		// Pages go from newest to oldest, like the messages in them
+		messages = append(messages, historyResponse.Messages...)
+		cursor := historyResponse.ResponseMetadata.NextCursor
+		if startTimestamp == "" || !historyResponse.HasMore || cursor == "" {
+			historyResponse.Messages = messages
+			return historyResponse, nil
+		}
+		params["cursor"] = cursor
 	}
+}
 
-	body, err := c.API("POST", "conversations.history", params, nil)
+// threadHistory fetches part of a thread, without its root message.
+func (c *SlackClient) threadHistory(channelID, startTimestamp, thread string, limit int) (*HistoryResponse, error) {
+	body, err := c.API("POST", "conversations.history", map[string]string{
+		"channel":   channelID,
+		"ts":        thread,
+		"oldest":    startTimestamp,
+		"inclusive": "true",
+		"limit":     strconv.Itoa(limit),
+	}, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	historyResponse := &HistoryResponse{}
-	err = json.Unmarshal(body, historyResponse)
-	if err != nil {
+	if err := decode("conversations.history", body, historyResponse); err != nil {
 		return nil, err
 	}
 
-	if !historyResponse.Ok {
-		return nil, fmt.Errorf("conversations.history response not OK: %s", body)
-	}
-
-	// If thread was specified, then we are fetching only part of a thread and
-	// should remove the first message if it has a reply count as we don't want
-	// the root message.
-	if thread != "" && historyResponse.Messages[0].ReplyCount != 0 && len(historyResponse.Messages) > 1 {
+	if len(historyResponse.Messages) > 1 && historyResponse.Messages[0].ReplyCount != 0 {
 		historyResponse.Messages = historyResponse.Messages[1:]
 	}
+	return historyResponse, nil
+}
 
-	if thread != "" || historyResponse.Messages[0].ReplyCount != 0 {
-		// Either we are deliberately fetching a subthread, or an entire thread.
-		return historyResponse, nil
+// HistoryBetween returns up to limit messages posted between oldest and
+// latest, newest first. Either bound may be empty.
+func (c *SlackClient) HistoryBetween(channelID, oldest, latest string, limit int) ([]Message, error) {
+	params := map[string]string{
+		"channel":   channelID,
+		"inclusive": "true",
+		"limit":     strconv.Itoa(limit),
+	}
+	if oldest != "" {
+		params["oldest"] = oldest
+	}
+	if latest != "" {
+		params["latest"] = latest
 	}
 
-	// Otherwise we read the general channel history
-	body, err = c.get("conversations.history",
-		map[string]string{
-			"channel":   channelID,
-			"oldest":    startTimestamp,
-			"inclusive": "true",
-			"limit":     strconv.Itoa(limit)})
+	body, err := c.get("conversations.history", params)
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal(body, historyResponse)
+	history := &HistoryResponse{}
+	if err := decode("conversations.history", body, history); err != nil {
+		return nil, err
+	}
+
+	return history.Messages, nil
+}
+
+// Message fetches a single message, which may be a thread reply.
+func (c *SlackClient) Message(channelID, ts string) (*Message, error) {
+	params := map[string]string{
+		"channel":   channelID,
+		"oldest":    ts,
+		"latest":    ts,
+		"inclusive": "true",
+		"limit":     "1",
+	}
+
+	for _, method := range []string{"conversations.history", "conversations.replies"} {
+		if method == "conversations.replies" {
+			params["ts"] = ts
+		}
+
+		body, err := c.get(method, params)
+		if err != nil {
+			return nil, err
+		}
+
+		history := &HistoryResponse{}
+		if err := decode(method, body, history); err != nil {
+			return nil, err
+		}
+
+		for _, msg := range history.Messages {
+			if msg.Ts == ts {
+				return &msg, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("message %s not found", ts)
+}
+
+// Replies returns the messages of a thread, starting with its parent.
+func (c *SlackClient) Replies(channelID, threadTs string) ([]Message, error) {
+	body, err := c.get("conversations.replies", map[string]string{
+		"channel": channelID,
+		"ts":      threadTs,
+		"limit":   "200",
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !historyResponse.Ok {
-		return nil, fmt.Errorf("conversations.history response not OK: %s", body)
+	history := &HistoryResponse{}
+	if err := decode("conversations.replies", body, history); err != nil {
+		return nil, err
 	}
-	c.log.Println(string(body))
-	c.log.Printf("%#v", historyResponse)
-	return historyResponse, nil
+
+	return history.Messages, nil
 }
 
 func (c *SlackClient) saveCache() error {
@@ -419,38 +1001,46 @@ func (c *SlackClient) UsernameForID(id string) (string, error) {
 		return name, nil
 	}
 
-	ur, err := c.users()
-	if err != nil {
-		return "", err
-	}
-
-	c.cache.Users = make(map[string]string)
-	for _, ch := range ur {
-		c.cache.Users[ch.ID] = ch.Name
-	}
-
-	err = c.saveCache()
-	if err != nil {
-		return "", err
+	if c.unresolvedUsers[id] {
+		return "", fmt.Errorf("no user with id %q", id)
 	}
 
-	if name, ok := c.cache.Users[id]; ok {
-		return name, nil
+	if !c.usersListFailed {
+		ur, err := c.users()
+		if err != nil {
+			// Likely missing permissions, try users.info from now on
+			c.usersListFailed = true
+		} else {
+			c.cache.Users = make(map[string]string)
+			for _, ch := range ur {
+				c.cache.Users[ch.ID] = ch.Name
+			}
+
+			err = c.saveCache()
+			if err != nil {
+				return "", err
+			}
+
+			if name, ok := c.cache.Users[id]; ok {
+				return name, nil
+			}
+		}
 	}
 
 	body, err := c.get("users.info", map[string]string{"user": id})
 	if err != nil {
+		c.markUnresolved(id)
 		return "", fmt.Errorf("no user with id %q: %w", id, err)
 	}
 
 	user := &UsersInfoResponse{}
-	err = json.Unmarshal(body, user)
-	if err != nil {
+	if err := decode("users.info", body, user); err != nil {
+		c.markUnresolved(id)
 		return "", err
 	}
 
-	if !user.Ok {
-		return "", errors.New("users.info response not OK")
+	if c.cache.Users == nil {
+		c.cache.Users = make(map[string]string)
 	}
 
 	c.cache.Users[id] = user.User.Name
@@ -462,6 +1052,13 @@ func (c *SlackClient) UsernameForID(id string) (string, error) {
 	return user.User.Name, nil
 }
 
+func (c *SlackClient) markUnresolved(id string) {
+	if c.unresolvedUsers == nil {
+		c.unresolvedUsers = make(map[string]bool)
+	}
+	c.unresolvedUsers[id] = true
+}
+
 func (c *SlackClient) ChannelIDForName(name string) (string, error) {
 	if id, ok := c.cache.Channels[name]; ok {
 		return id, nil
@@ -493,29 +1090,817 @@ func (c *SlackClient) ChannelIDForName(name string) (string, error) {
 	return "", fmt.Errorf("could not find any channel with name %q", name)
 }
 
-func (c *SlackClient) GetLocation() *time.Location {
-	return c.tz
-}
+var channelIDPattern = regexp.MustCompile(`^[CGD][A-Z0-9]{8,}$`)
 
-func (c *SlackClient) SendMessage(channelID string, message string) (*SendMessageResponse, error) {
-	body, err := c.post("chat.postMessage",
-		map[string]string{}, &SendMessage{
-			Channel: channelID,
-			Text:    message,
-		})
-	if err != nil {
-		return nil, err
+var userIDPattern = regexp.MustCompile(`^[UW][A-Z0-9]{8,}$`)
+
+// ResolveUser returns the ID for a user given either their ID, their name
+// optionally prefixed with '@', or a <@U0123ABCD> mention.
+func (c *SlackClient) ResolveUser(name string) (string, error) {
+	if m := referenceRe.FindStringSubmatch(name); m != nil && m[1] == "@" {
+		return m[2], nil
+	}
+	if !strings.HasPrefix(name, "@") && userIDPattern.MatchString(name) {
+		return name, nil
 	}
 
-	response := &SendMessageResponse{}
-	err = json.Unmarshal(body, response)
+	name = strings.TrimPrefix(name, "@")
+	if name == "" {
+		return "", errors.New("empty user name")
+	}
+
+	for id, username := range c.cache.Users {
+		if username == name {
+			return id, nil
+		}
+	}
+
+	users, err := c.users()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	if !response.OK {
-		return nil, fmt.Errorf("chat.postMessage response not OK: %s", body)
+	if c.cache.Users == nil {
+		c.cache.Users = make(map[string]string)
+	}
+	for _, user := range users {
+		c.cache.Users[user.ID] = user.Name
+	}
+	if err := c.saveCache(); err != nil {
+		return "", err
 	}
 
-	return response, nil
+	for _, user := range users {
+		if user.Name == name {
+			return user.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find any user named %q", name)
+}
+
+// ResolveChannel returns the ID for a channel given either its ID or its
+// name, optionally prefixed with '#'. Names are resolved through the channel
+// cache, which is populated from conversations.list on a miss.
+func (c *SlackClient) ResolveChannel(name string) (string, error) {
+	if !strings.HasPrefix(name, "#") && channelIDPattern.MatchString(name) {
+		return name, nil
+	}
+
+	name = strings.TrimPrefix(name, "#")
+	if name == "" {
+		return "", errors.New("empty channel name")
+	}
+
+	if id, ok := c.cache.Channels[name]; ok {
+		return id, nil
+	}
+
+	channels, err := c.conversations()
+	if err != nil {
+		return "", err
+	}
+
+	var matches []Channel
+	for _, ch := range channels {
+		if ch.Name == name {
+			matches = append(matches, ch)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("could not find any channel named %q (private channels are only listed if you are a member)", name)
+	case 1:
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, ch := range matches {
+			ids = append(ids, ch.ID)
+		}
+		return "", fmt.Errorf("channel name %q is ambiguous, use one of the IDs instead: %s", name, strings.Join(ids, ", "))
+	}
+
+	channel := matches[0]
+	if channel.IsPrivate && !channel.IsMember {
+		return "", fmt.Errorf("channel %q is private and you are not a member", name)
+	}
+
+	if c.cache.Channels == nil {
+		c.cache.Channels = make(map[string]string)
+	}
+	c.cache.Channels[name] = channel.ID
+	if err := c.saveCache(); err != nil {
+		return "", err
+	}
+
+	return channel.ID, nil
+}
+
+// CurrentUserID returns the ID of the user the client is authenticated as.
+func (c *SlackClient) CurrentUserID() (string, error) {
+	if c.userID != "" {
+		return c.userID, nil
+	}
+
+	body, err := c.get("auth.test", map[string]string{})
+	if err != nil {
+		return "", err
+	}
+
+	response := &AuthTestResponse{}
+	if err := decode("auth.test", body, response); err != nil {
+		return "", err
+	}
+
+	c.userID = response.UserID
+	c.botID = response.BotID
+	return c.userID, nil
+}
+
+// Team is the workspace the client is connected to.
+type Team struct {
+	ID     string
+	Name   string
+	Domain string
+}
+
+// TeamInfo returns the workspace the client is connected to, fetched once.
+func (c *SlackClient) TeamInfo() (*Team, error) {
+	if c.teamInfo != nil {
+		return c.teamInfo, nil
+	}
+
+	body, err := c.get("team.info", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &struct {
+		Team Team
+	}{}
+	if err := decode("team.info", body, response); err != nil {
+		return nil, err
+	}
+
+	c.teamInfo = &response.Team
+	return c.teamInfo, nil
+}
+
+// CustomEmoji returns the names of the workspace's custom emoji, aliases
+// included, sorted. They're fetched once.
+func (c *SlackClient) CustomEmoji() ([]string, error) {
+	if c.customEmoji != nil {
+		return c.customEmoji, nil
+	}
+
+	body, err := c.get("emoji.list", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &struct {
+		Emoji map[string]string
+	}{}
+	if err := decode("emoji.list", body, response); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(response.Emoji))
+	for name := range response.Emoji {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c.customEmoji = names
+	return names, nil
+}
+
+// IsBot reports whether the client is authenticated with a bot token.
+func (c *SlackClient) IsBot() (bool, error) {
+	if _, err := c.CurrentUserID(); err != nil {
+		return false, err
+	}
+	return c.botID != "", nil
+}
+
+// errScopesUnknown is returned by CheckScopes for tokens Slack doesn't
+// list the scopes of, like the session tokens of browser logins.
+var errScopesUnknown = errors.New("Slack doesn't list the scopes of this token")
+
+// CheckScopes returns which of the required scopes the token lacks, as
+// reported by Slack along with auth.test.
+func (c *SlackClient) CheckScopes(required []string) ([]string, error) {
+	body, err := c.get("auth.test", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	if err := decode("auth.test", body, &AuthTestResponse{}); err != nil {
+		return nil, err
+	}
+
+	header := c.transport.oauthScopes()
+	if header == "" {
+		return nil, errScopesUnknown
+	}
+
+	granted := make(map[string]bool)
+	for _, scope := range strings.Split(header, ",") {
+		granted[strings.TrimSpace(scope)] = true
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing, nil
+}
+
+// Files larger than this are never downloaded for display.
+const maxFileContentSize = 1 << 20
+
+// FetchFileContent returns the content of a text file or snippet.
+func (c *SlackClient) FetchFileContent(fileID string) ([]byte, error) {
+	body, err := c.get("files.info", map[string]string{"file": fileID})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &FileInfoResponse{}
+	if err := decode("files.info", body, response); err != nil {
+		return nil, err
+	}
+
+	if !response.File.IsText() {
+		return nil, fmt.Errorf("file %q is not a text file (%s)", response.File.Name, response.File.Mimetype)
+	}
+
+	if response.File.Size > maxFileContentSize {
+		return nil, fmt.Errorf("file %q is too large to display (%d bytes)", response.File.Name, response.File.Size)
+	}
+
+	return []byte(response.Content), nil
+}
+
+// Errors returned by the stars API once a workspace has moved over to
+// saved items.
+var starsDeprecatedErrors = map[string]bool{
+	"method_deprecated": true,
+	"unknown_method":    true,
+}
+
+// SaveMessage saves a message for later. The legacy stars API is tried
+// first, falling back to the saved items API where stars are deprecated.
+func (c *SlackClient) SaveMessage(channelID, ts string) error {
+	return c.starsOrSaved(
+		"stars.add", map[string]string{"channel": channelID, "timestamp": ts},
+		"saved.add", map[string]string{"item_type": "message", "item_id": channelID, "ts": ts},
+	)
+}
+
+// RemoveSaved removes a message from the saved items.
+func (c *SlackClient) RemoveSaved(channelID, ts string) error {
+	return c.starsOrSaved(
+		"stars.remove", map[string]string{"channel": channelID, "timestamp": ts},
+		"saved.delete", map[string]string{"item_type": "message", "item_id": channelID, "ts": ts},
+	)
+}
+
+func (c *SlackClient) starsOrSaved(starsMethod string, starsParams map[string]string, savedMethod string, savedParams map[string]string) error {
+	body, err := c.API("POST", starsMethod, starsParams, nil)
+	if err != nil {
+		return err
+	}
+
+	err = decode(starsMethod, body, nil)
+	if !starsDeprecated(err) {
+		return err
+	}
+
+	body, err = c.API("POST", savedMethod, savedParams, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode(savedMethod, body, nil)
+}
+
+func starsDeprecated(err error) bool {
+	var slackErr *SlackError
+	return errors.As(err, &slackErr) && starsDeprecatedErrors[slackErr.Code]
+}
+
+// ListSaved returns the messages saved for later.
+func (c *SlackClient) ListSaved() ([]SavedItem, error) {
+	items := []SavedItem{}
+	stars := &StarsListResponse{}
+	for {
+		body, err := c.get("stars.list", map[string]string{
+			"cursor": stars.ResponseMetadata.NextCursor,
+			"limit":  "100",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := decode("stars.list", body, stars); starsDeprecated(err) {
+			return c.listSavedItems()
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, item := range stars.Items {
+			if item.Type != "message" {
+				continue
+			}
+			message := item.Message
+			items = append(items, SavedItem{ChannelID: item.Channel, Ts: message.Ts, Message: &message})
+		}
+
+		if stars.ResponseMetadata.NextCursor == "" {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+func (c *SlackClient) listSavedItems() ([]SavedItem, error) {
+	items := []SavedItem{}
+	saved := &SavedListResponse{}
+	for {
+		body, err := c.get("saved.list", map[string]string{
+			"cursor": saved.ResponseMetadata.NextCursor,
+			"limit":  "100",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := decode("saved.list", body, saved); err != nil {
+			return nil, err
+		}
+
+		for _, item := range saved.SavedItems {
+			if item.ItemType != "message" {
+				continue
+			}
+			items = append(items, SavedItem{ChannelID: item.ItemID, Ts: item.Ts})
+		}
+
+		if saved.ResponseMetadata.NextCursor == "" {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+func (c *SlackClient) GetLocation() *time.Location {
+	return c.tz
+}
+
+func (c *SlackClient) SendMessage(channelID string, message string) (*SendMessageResponse, error) {
+	return c.SendMessageAs(channelID, message, Sender{})
+}
+
+// SendMessageAs posts a message with a custom username or icon, which
+// needs a bot token.
+func (c *SlackClient) SendMessageAs(channelID, message string, as Sender) (*SendMessageResponse, error) {
+	if as != (Sender{}) {
+		isBot, err := c.IsBot()
+		if err != nil {
+			return nil, err
+		}
+		if !isBot {
+			return nil, errors.New("posting with a custom username or icon needs a bot token")
+		}
+	}
+
+	body, err := c.post("chat.postMessage",
+		map[string]string{}, &SendMessage{
+			Channel: channelID,
+			Text:    message,
+			Sender:  as,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SendMessageResponse{}
+	if err := decode("chat.postMessage", body, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// UpdateMessage replaces the text of a message previously sent by the user.
+func (c *SlackClient) UpdateMessage(channelID, ts, message string) (*SendMessageResponse, error) {
+	body, err := c.post("chat.update",
+		map[string]string{}, &SendMessage{
+			TS:      ts,
+			Channel: channelID,
+			Text:    message,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SendMessageResponse{}
+	if err := decode("chat.update", body, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// DeleteMessage deletes a message, which must have been sent by the user.
+func (c *SlackClient) DeleteMessage(channelID, ts string) error {
+	body, err := c.API("POST", "chat.delete", map[string]string{
+		"channel": channelID,
+		"ts":      ts,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("chat.delete", body, nil)
+}
+
+// AddReaction reacts to a message with the emoji called name, e.g. "+1".
+func (c *SlackClient) AddReaction(channelID, ts, name string) error {
+	body, err := c.API("POST", "reactions.add", map[string]string{
+		"channel":   channelID,
+		"timestamp": ts,
+		"name":      name,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("reactions.add", body, nil)
+}
+
+// Bookmark is a link bookmarked in a channel.
+type Bookmark struct {
+	ID    string
+	Title string
+	Link  string
+	Emoji string
+	Type  string
+}
+
+type BookmarksResponse struct {
+	Bookmarks []Bookmark
+}
+
+// ListBookmarks returns the links bookmarked in a channel.
+func (c *SlackClient) ListBookmarks(channelID string) ([]Bookmark, error) {
+	body, err := c.get("bookmarks.list", map[string]string{"channel_id": channelID})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BookmarksResponse{}
+	if err := decode("bookmarks.list", body, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Bookmarks, nil
+}
+
+// InviteUser adds a user to a channel.
+func (c *SlackClient) InviteUser(channelID, userID string) error {
+	body, err := c.API("POST", "conversations.invite", map[string]string{
+		"channel": channelID,
+		"users":   userID,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("conversations.invite", body, nil)
+}
+
+// RemoveUser removes a user from a channel.
+func (c *SlackClient) RemoveUser(channelID, userID string) error {
+	body, err := c.API("POST", "conversations.kick", map[string]string{
+		"channel": channelID,
+		"user":    userID,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("conversations.kick", body, nil)
+}
+
+// ArchiveChannel archives a channel, making it read only.
+func (c *SlackClient) ArchiveChannel(channelID string) error {
+	body, err := c.API("POST", "conversations.archive", map[string]string{"channel": channelID}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("conversations.archive", body, nil)
+}
+
+// JoinChannel joins a public channel.
+func (c *SlackClient) JoinChannel(channelID string) error {
+	body, err := c.API("POST", "conversations.join", map[string]string{"channel": channelID}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("conversations.join", body, nil)
+}
+
+// UnarchiveChannel brings an archived channel back.
+func (c *SlackClient) UnarchiveChannel(channelID string) error {
+	body, err := c.API("POST", "conversations.unarchive", map[string]string{"channel": channelID}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("conversations.unarchive", body, nil)
+}
+
+// DndInfo is the user's Do Not Disturb status, with times as Unix seconds.
+type DndInfo struct {
+	Enabled       bool  `json:"dnd_enabled"` // a DND schedule is set
+	NextStart     int64 `json:"next_dnd_start_ts"`
+	NextEnd       int64 `json:"next_dnd_end_ts"`
+	SnoozeEnabled bool  `json:"snooze_enabled"`
+	SnoozeEnd     int64 `json:"snooze_endtime"`
+}
+
+// Active reports whether notifications are paused at the given time.
+func (d *DndInfo) Active(now time.Time) bool {
+	if d == nil {
+		return false
+	}
+
+	ts := now.Unix()
+	if d.SnoozeEnabled && ts < d.SnoozeEnd {
+		return true
+	}
+	return d.Enabled && d.NextStart <= ts && ts < d.NextEnd
+}
+
+// DndInfo returns the Do Not Disturb status of the current user.
+func (c *SlackClient) DndInfo() (*DndInfo, error) {
+	body, err := c.get("dnd.info", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	info := &DndInfo{}
+	if err := decode("dnd.info", body, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// SetSnooze pauses notifications for the given number of minutes.
+func (c *SlackClient) SetSnooze(minutes int) error {
+	body, err := c.API("POST", "dnd.setSnooze", map[string]string{
+		"num_minutes": strconv.Itoa(minutes),
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("dnd.setSnooze", body, nil)
+}
+
+// EndSnooze resumes notifications paused with SetSnooze.
+func (c *SlackClient) EndSnooze() error {
+	body, err := c.API("POST", "dnd.endSnooze", map[string]string{}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("dnd.endSnooze", body, nil)
+}
+
+// Profile is what users.info tells about a user. Fields the workspace
+// doesn't share with us are empty.
+type Profile struct {
+	ID       string
+	Name     string
+	RealName string `json:"real_name"`
+	IsBot    bool   `json:"is_bot"`
+	TZ       string `json:"tz"`
+	TZLabel  string `json:"tz_label"`
+	TZOffset int    `json:"tz_offset"` // seconds from UTC
+	Details  struct {
+		Title       string
+		DisplayName string `json:"display_name"`
+		StatusText  string `json:"status_text"`
+		StatusEmoji string `json:"status_emoji"`
+		Email       string
+		Phone       string
+	} `json:"profile"`
+}
+
+// UserProfile returns the profile of a user, cached for the session.
+func (c *SlackClient) UserProfile(userID string) (*Profile, error) {
+	if profile, ok := c.profiles[userID]; ok {
+		return profile, nil
+	}
+
+	body, err := c.get("users.info", map[string]string{"user": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &struct{ User Profile }{}
+	if err := decode("users.info", body, response); err != nil {
+		return nil, err
+	}
+
+	if c.profiles == nil {
+		c.profiles = make(map[string]*Profile)
+	}
+	c.profiles[userID] = &response.User
+	return &response.User, nil
+}
+
+// UploadSnippet shares content as a code snippet, which Slack highlights
+// according to filetype, e.g. "go" or "python".
+func (c *SlackClient) UploadSnippet(channelID, content, filename, filetype string) error {
+	return c.upload(channelID, filename, filetype, []byte(content))
+}
+
+// UploadFile shares a local file in a channel.
+func (c *SlackClient) UploadFile(channelID, file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	return c.upload(channelID, filepath.Base(file), "", content)
+}
+
+// upload shares content as a file, highlighted as snippetType unless it's
+// empty.
+func (c *SlackClient) upload(channelID, filename, snippetType string, content []byte) error {
+	params := map[string]string{
+		"filename": filename,
+		"length":   strconv.Itoa(len(content)),
+	}
+	if snippetType != "" {
+		params["snippet_type"] = snippetType
+	}
+
+	body, err := c.get("files.getUploadURLExternal", params)
+	if err != nil {
+		return err
+	}
+
+	upload := &struct {
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+	}{}
+	if err := decode("files.getUploadURLExternal", body, upload); err != nil {
+		return err
+	}
+
+	// The upload URL is signed, so this is a plain request
+	resp, err := c.httpClient.Post(upload.UploadURL, "application/octet-stream", bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("uploading %s failed: %s", filename, resp.Status)
+	}
+
+	files, err := json.Marshal([]map[string]string{{"id": upload.FileID, "title": filename}})
+	if err != nil {
+		return err
+	}
+
+	body, err = c.API("POST", "files.completeUploadExternal", map[string]string{
+		"files":      string(files),
+		"channel_id": channelID,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	return decode("files.completeUploadExternal", body, nil)
+}
+
+// Mention is a message mentioning the current user, found by searching
+// all channels.
+type Mention struct {
+	Message
+	Channel   struct{ ID, Name string }
+	Permalink string
+}
+
+// UnmarshalJSON decodes the fields of both the mention and its message, as
+// the message's own UnmarshalJSON would otherwise take over.
+func (m *Mention) UnmarshalJSON(data []byte) error {
+	if err := m.Message.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	extra := struct {
+		Channel   *struct{ ID, Name string }
+		Permalink string
+	}{Channel: &m.Channel}
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	m.Permalink = extra.Permalink
+
+	return nil
+}
+
+// Mentions returns the latest messages mentioning the current user, newest
+// first.
+func (c *SlackClient) Mentions(limit int) ([]Mention, error) {
+	userID, err := c.CurrentUserID()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.SearchMessages("<@"+userID+">", limit, 1)
+	if err != nil {
+		return nil, err
+	}
+	return results.Matches, nil
+}
+
+// SearchResults is a page of messages matching a search.
+type SearchResults struct {
+	Matches []Mention
+	Total   int
+	Page    int
+	Pages   int
+}
+
+// SearchMessages returns the given page, counting from 1, of the messages
+// matching query across all channels, newest first.
+func (c *SlackClient) SearchMessages(query string, count, page int) (*SearchResults, error) {
+	body, err := c.get("search.messages", map[string]string{
+		"query":    query,
+		"sort":     "timestamp",
+		"sort_dir": "desc",
+		"count":    strconv.Itoa(count),
+		"page":     strconv.Itoa(page),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &struct {
+		Messages struct {
+			Matches []Mention
+			Total   int
+			Paging  struct {
+				Page  int
+				Pages int
+			}
+		}
+	}{}
+	if err := decode("search.messages", body, response); err != nil {
+		return nil, err
+	}
+
+	return &SearchResults{
+		Matches: response.Messages.Matches,
+		Total:   response.Messages.Total,
+		Page:    response.Messages.Paging.Page,
+		Pages:   response.Messages.Paging.Pages,
+	}, nil
+}
+
+type PermalinkResponse struct {
+	Ok        bool   `json:"ok"`
+	Error     string `json:"error"`
+	Permalink string `json:"permalink"`
+}
+
+// Permalink returns the permanent link to a message.
+func (c *SlackClient) Permalink(channelID, ts string) (string, error) {
+	body, err := c.get("chat.getPermalink", map[string]string{
+		"channel":    channelID,
+		"message_ts": ts,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	response := &PermalinkResponse{}
+	if err := decode("chat.getPermalink", body, response); err != nil {
+		return "", err
+	}
+
+	return response.Permalink, nil
 }
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	fake, client := newFakeSlack(t)
+	fake.respond("conversations.history", ok(map[string]any{
+		"messages": []map[string]any{
+			{"type": "message", "user": "U2", "text": "second", "ts": "1700000002.000200"},
+			{"type": "message", "user": "U1", "text": "first", "ts": "1700000001.000100"},
+		},
+		"has_more": false,
+	}))
+
+	history, err := client.History("C1", "1700000001.000100", "", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(history.Messages))
+	}
+	if got := history.Messages[0]; got.Text != "second" || got.User != "U2" || got.Ts != "1700000002.000200" {
+		t.Errorf("first message is %+v", got)
+	}
+
+	calls := fake.callsTo("conversations.history")
+	params := calls[len(calls)-1]
+	if params.Get("channel") != "C1" || params.Get("oldest") != "1700000001.000100" || params.Get("limit") != "20" {
+		t.Errorf("called with %v", params)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	fake, client := newFakeSlack(t)
+
+	var sent SendMessage
+	fake.handle("chat.postMessage", func(_ url.Values, body []byte) any {
+		if err := json.Unmarshal(body, &sent); err != nil {
+			t.Errorf("invalid request body %q: %v", body, err)
+		}
+		return ok(map[string]any{
+			"channel": "C1",
+			"ts":      "1700000003.000300",
+			"message": map[string]any{"user": "U1", "text": sent.Text, "ts": "1700000003.000300"},
+		})
+	})
+
+	resp, err := client.SendMessage("C1", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sent.Channel != "C1" || sent.Text != "hello" {
+		t.Errorf("sent %+v", sent)
+	}
+	if resp.TS != "1700000003.000300" || resp.Message.Text != "hello" {
+		t.Errorf("got response %+v", resp)
+	}
+}
+
+func TestUploadTimesOut(t *testing.T) {
+	fake, client := newFakeSlack(t)
+	client.SetTimeouts(50*time.Millisecond, time.Second, 0)
+
+	fake.respond("files.getUploadURLExternal", ok(map[string]any{
+		"upload_url": fake.URL + "/api/upload",
+		"file_id":    "F1",
+	}))
+	// Stalls until the test is over, the server waits for it when closing
+	stalled := make(chan struct{})
+	t.Cleanup(func() { close(stalled) })
+	fake.handle("upload", func(url.Values, []byte) any {
+		<-stalled
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- client.UploadSnippet("C1", "package main", "main.go", "go") }()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("upload succeeded, want a timeout")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("upload didn't time out")
+	}
+	if calls := fake.callsTo("files.completeUploadExternal"); len(calls) != 0 {
+		t.Errorf("completed the upload %d times after it failed", len(calls))
+	}
+}
+
+func TestUsernameForMessage(t *testing.T) {
+	fake, client := newFakeSlack(t)
+	fake.respond("users.list", ok(map[string]any{
+		"members": []map[string]any{{"id": "U1", "name": "alice"}},
+	}))
+	fake.respond("users.info", ok(map[string]any{
+		"user": map[string]any{"id": "U2", "name": "bob"},
+	}))
+
+	for _, tc := range []struct {
+		user, want string
+	}{
+		{"U1", "alice"}, // from users.list
+		{"U2", "bob"},   // not listed, from users.info
+	} {
+		got, err := client.UsernameForMessage(Message{User: tc.user})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s is %q, want %q", tc.user, got, tc.want)
+		}
+	}
+
+	// Cached once looked up
+	client.UsernameForMessage(Message{User: "U2"})
+	if n := len(fake.callsTo("users.info")); n != 1 {
+		t.Errorf("users.info called %d times, want 1", n)
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	fake, client := newFakeSlack(t)
+	fake.respond("conversations.history", slackError("channel_not_found"))
+	fake.respond("chat.postMessage", slackError("not_in_channel"))
+	fake.respond("conversations.info", slackError("missing_scope"))
+
+	_, err := client.History("C1", "", "", 20)
+	assertSlackError(t, err, "conversations.history", "channel_not_found")
+
+	_, err = client.SendMessage("C1", "hello")
+	assertSlackError(t, err, "chat.postMessage", "not_in_channel")
+
+	_, err = client.ChannelInfo("C1")
+	assertSlackError(t, err, "conversations.info", "missing_scope")
+}
+
+func assertSlackError(t *testing.T, err error, method, code string) {
+	t.Helper()
+
+	var slackErr *SlackError
+	if !errors.As(err, &slackErr) {
+		t.Fatalf("got %v, want a %s error", err, code)
+	}
+	if slackErr.Method != method || slackErr.Code != code {
+		t.Errorf("got %s failing with %s, want %s failing with %s", slackErr.Method, slackErr.Code, method, code)
+	}
+}
+
+func TestHistoryCatchesUpOnBursts(t *testing.T) {
+	fake, client := newFakeSlack(t)
+
+	// 45 messages since the last poll, newest first, the newest being a
+	// thread parent
+	var burst []map[string]any
+	for i := 45; i > 0; i-- {
+		burst = append(burst, map[string]any{"text": "burst", "ts": fmt.Sprintf("1700000000.%06d", i)})
+	}
+	burst[0]["reply_count"] = 2
+
+	fake.handle("conversations.history", func(params url.Values, _ []byte) any {
+		start, _ := strconv.Atoi(params.Get("cursor"))
+		limit, _ := strconv.Atoi(params.Get("limit"))
+		end := start + limit
+		if end >= len(burst) {
+			return ok(map[string]any{"messages": burst[start:]})
+		}
+		return ok(map[string]any{
+			"messages":          burst[start:end],
+			"has_more":          true,
+			"response_metadata": map[string]any{"next_cursor": strconv.Itoa(end)},
+		})
+	})
+
+	history, err := client.History("C1", "1700000000.000000", "", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.Messages) != len(burst) {
+		t.Fatalf("got %d messages, want all %d", len(history.Messages), len(burst))
+	}
+	if first, last := history.Messages[0].Ts, history.Messages[len(burst)-1].Ts; first != "1700000000.000045" || last != "1700000000.000001" {
+		t.Errorf("got messages from %s to %s, want newest first", first, last)
+	}
+	if n := len(fake.callsTo("conversations.history")); n != 3 {
+		t.Errorf("fetched %d pages, want 3", n)
+	}
+}
+
+func TestUsernameForMessageWithoutUsersRead(t *testing.T) {
+	fake, client := newFakeSlack(t)
+	fake.respond("users.list", slackError("missing_scope"))
+	fake.respond("users.info", slackError("missing_scope"))
+
+	for _, tc := range []struct {
+		message Message
+		want    string
+	}{
+		{Message{User: "U1", UserProfile: &UserProfile{DisplayName: "Alice"}}, "Alice"},
+		{Message{BotID: "B1", BotProfile: &BotProfile{Name: "deploy"}}, "deploy"},
+		{Message{BotID: "B2", Username: "alertmanager"}, "alertmanager"},
+		{Message{User: "U2"}, "U2"},
+		{Message{BotID: "B3"}, "bot B3"},
+	} {
+		got, err := client.UsernameForMessage(tc.message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("got %q, want %q for %+v", got, tc.want, tc.message)
+		}
+	}
+
+	// Failed lookups aren't retried every poll
+	client.UsernameForMessage(Message{User: "U2"})
+	if n := len(fake.callsTo("users.list")); n != 1 {
+		t.Errorf("users.list called %d times, want 1", n)
+	}
+	if n := len(fake.callsTo("users.info")); n != 2 {
+		t.Errorf("users.info called %d times, want once per user", n)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, tc := range []struct {
+		body    string
+		wantErr string
+	}{
+		{`{"ok":true}`, ""},
+		{`{"ok":false,"error":"not_in_channel"}`, "chat.postMessage failed: not_in_channel (join the channel first)"},
+		{`{"ok":false,"error":"msg_too_long","response_metadata":{"warnings":["superfluous_charset"]}}`,
+			"chat.postMessage failed: msg_too_long (the message is too long), warnings: superfluous_charset"},
+		{`{"ok":false,"error":"team_added_to_org"}`, "chat.postMessage failed: team_added_to_org"},
+		{`{"ok":false}`, "chat.postMessage failed: unknown_error"},
+	} {
+		err := decode("chat.postMessage", []byte(tc.body), nil)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s: got %v", tc.body, err)
+		case tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr):
+			t.Errorf("%s: got %v, want %s", tc.body, err, tc.wantErr)
+		}
+	}
+
+	var slackErr *SlackError
+	if err := decode("chat.postMessage", []byte("<html>"), nil); err == nil || errors.As(err, &slackErr) {
+		t.Errorf("got %v for an invalid response, want a decoding error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var codeBlockRe = regexp.MustCompile("(?s)```(.*?)```")
+
+// Slack escapes these in message text, code included
+var unescapeText = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+type clipboardMsg struct {
+	status string
+	err    error
+}
+
+// extractCodeBlocks returns the contents of the fenced code blocks in a
+// message, without the backticks.
+func extractCodeBlocks(text string) []string {
+	var blocks []string
+	for _, match := range codeBlockRe.FindAllStringSubmatch(text, -1) {
+		code := strings.TrimPrefix(match[1], "\n")
+		code = strings.TrimSuffix(code, "\n")
+		blocks = append(blocks, unescapeText.Replace(code))
+	}
+
+	return blocks
+}
+
+// copyCodeBlock copies a code block of the selected message. Pressing it
+// again on the same message copies the next block.
+func (m *model) copyCodeBlock() tea.Cmd {
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	blocks := extractCodeBlocks(selected.message.Text)
+	if len(blocks) == 0 {
+		m.setStatus("no code block in this message")
+		return nil
+	}
+
+	if m.copiedTs == selected.id {
+		m.copiedBlock = (m.copiedBlock + 1) % len(blocks)
+	} else {
+		m.copiedTs, m.copiedBlock = selected.id, 0
+	}
+
+	status := "code block copied"
+	if len(blocks) > 1 {
+		status = fmt.Sprintf("code block %d/%d copied, press again for the next", m.copiedBlock+1, len(blocks))
+	}
+	return copyToClipboard(m.output, blocks[m.copiedBlock], status)
+}
+
+// copyRaw copies the JSON of the selected message as Slack sent it, to
+// diagnose rendering issues.
+func (m *model) copyRaw() tea.Cmd {
+	if !m.debug {
+		m.setStatus("start with --debug to copy the raw messages")
+		return nil
+	}
+
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return nil
+	}
+	if len(selected.message.Raw) == 0 {
+		m.setStatus("this message hasn't been received from Slack yet")
+		return nil
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, selected.message.Raw, "", "  "); err != nil {
+		m.logError(fmt.Errorf("could not format the message: %w", err))
+		return nil
+	}
+	return copyToClipboard(m.output, out.String(), "raw JSON copied")
+}
+
+// copyToClipboard uses the platform's clipboard tool, falling back to the
+// OSC 52 escape sequence most terminals support, also over SSH, written to
+// out along with the rest of the TUI.
+func copyToClipboard(out io.Writer, text, status string) tea.Cmd {
+	return func() tea.Msg {
+		var tools [][]string
+		switch {
+		case runtime.GOOS == "darwin":
+			tools = [][]string{{"pbcopy"}}
+		case os.Getenv("WAYLAND_DISPLAY") != "":
+			tools = [][]string{{"wl-copy"}}
+		case os.Getenv("DISPLAY") != "":
+			tools = [][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+		}
+
+		for _, tool := range tools {
+			if _, err := exec.LookPath(tool[0]); err != nil {
+				continue
+			}
+
+			cmd := exec.Command(tool[0], tool[1:]...)
+			cmd.Stdin = strings.NewReader(text)
+			if err := cmd.Run(); err != nil {
+				return clipboardMsg{err: fmt.Errorf("%s failed: %w", tool[0], err)}
+			}
+			return clipboardMsg{status: status}
+		}
+
+		_, err := io.WriteString(out, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte(text))+"\a")
+		return clipboardMsg{status: status, err: err}
+	}
+}
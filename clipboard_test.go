@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"runtime"
+	"testing"
+)
+
+func TestCopyToClipboardFallsBackToOSC52(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("pbcopy is always used on macOS")
+	}
+	// Without a display there's no clipboard tool to run
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	var out bytes.Buffer
+	msg := copyToClipboard(&out, "hello", "copied")().(clipboardMsg)
+	if msg.err != nil || msg.status != "copied" {
+		t.Fatalf("got %+v", msg)
+	}
+
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("hello")) + "\a"
+	if out.String() != want {
+		t.Errorf("wrote %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import "strings"
+
+// Lines of a collapsed message that are still shown
+const collapsedPreviewLines = 5
+
+// collapse returns the text to show for msg, cut down to a preview when
+// it's too long and hasn't been expanded, and how many lines were cut.
+func (m *model) collapse(msg formattedMessage) (string, int) {
+	limit := m.config.CollapseLines
+	if limit <= 0 || m.expanded[msg.id] {
+		return msg.message.Text, 0
+	}
+
+	lines := strings.Split(msg.message.Text, "\n")
+	if len(lines) <= limit {
+		return msg.message.Text, 0
+	}
+
+	return strings.Join(lines[:collapsedPreviewLines], "\n"), len(lines) - collapsedPreviewLines
+}
+
+// toggleExpanded shows the selected message in full, or collapses it again.
+func (m *model) toggleExpanded() {
+	selected, ok := m.selectedMessage()
+	if !ok || m.toggleMembershipRun() {
+		return
+	}
+
+	limit := m.config.CollapseLines
+	if limit <= 0 || strings.Count(selected.message.Text, "\n") < limit {
+		m.setStatus("this message isn't collapsed")
+		return
+	}
+
+	if m.expanded == nil {
+		m.expanded = make(map[string]bool)
+	}
+	m.expanded[selected.id] = !m.expanded[selected.id]
+	m.updateViewportContent()
+
+	// Start reading the expanded message from its first line
+	if row := m.messageRows[m.selected]; row >= 0 {
+		m.viewport.SetYOffset(row)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// channelSwitchMsg is sent once a channel reference has been resolved and
+// the UI can switch to it.
+type channelSwitchMsg struct {
+	channelID   string
+	channelName string
+	channel     *Channel // nil if the channel info isn't available
+	err         error
+
+	// Set when going back or forward, with the position in the history
+	fromHistory  bool
+	historyIndex int
+}
+
+// The slash commands, other text starting with a slash is sent as is
+var commandNames = map[string]bool{
+	"join": true, "filter": true, "reactfilter": true, "unhandled": true,
+	"editor": true, "snippet": true, "mute": true, "unmute": true,
+	"dnd": true, "date": true, "export": true, "bookmarks": true,
+	"search": true, "activity": true, "invite": true, "kick": true,
+	"crosspost": true, "archive": true, "unarchive": true, "rejoin": true,
+	"switch": true, "saved": true,
+}
+
+// isCommand reports whether input runs a slash command rather than being
+// sent, so a path like "/etc/hosts" or "/shrug" goes out as typed.
+func isCommand(input string) bool {
+	input = strings.TrimSpace(input)
+	name, _, _ := strings.Cut(strings.TrimPrefix(input, "/"), " ")
+	return strings.HasPrefix(input, "/") && commandNames[name]
+}
+
+// unescapeCommand sends "//join #general" as "/join #general", for talking
+// about a command without running it.
+func unescapeCommand(text string) string {
+	if rest := strings.TrimPrefix(text, "/"); strings.HasPrefix(text, "//") && isCommand(rest) {
+		return rest
+	}
+	return text
+}
+
+// runCommand handles slash commands typed into the input, e.g.
+// "/join #general".
+func (m *model) runCommand(input string) tea.Cmd {
+	name, args, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(input), "/"), " ")
+	args = strings.TrimSpace(args)
+
+	// Archiving needs the command run twice in a row
+	confirmArchive := m.pendingArchive
+	m.pendingArchive = false
+
+	// So does crossposting to many channels, with the same arguments
+	confirmCrosspost := m.pendingCrosspost != "" && m.pendingCrosspost == args
+	m.pendingCrosspost = ""
+
+	switch name {
+	case "join":
+		if args == "" {
+			m.setStatus("usage: /join <#channel-name|channelID>")
+			return nil
+		}
+		return joinChannel(m.client, args)
+	case "filter":
+		m.setFilter(args)
+		return nil
+	case "reactfilter":
+		m.setReactFilter(args)
+		return nil
+	case "unhandled":
+		m.toggleUnhandled()
+		return nil
+	case "editor":
+		// The input holds the command itself, only a staged draft carries over
+		return m.composeInEditor(m.staged, args == "send")
+	case "snippet":
+		return m.snippetCommand(args)
+	case "mute":
+		return m.setNotifyLevel(notifyNone, "channel muted")
+	case "unmute":
+		return m.setNotifyLevel(notifyMentions, "channel unmuted, notifying on mentions")
+	case "dnd":
+		return m.snooze(args)
+	case "date":
+		return m.dateCommand(args)
+	case "export":
+		return m.exportCommand(args)
+	case "bookmarks":
+		return m.bookmarksCommand(args)
+	case "search":
+		return m.searchCommand(args)
+	case "activity":
+		return m.activityCommand(args)
+	case "invite":
+		return m.memberCommand(args, true)
+	case "kick":
+		return m.memberCommand(args, false)
+	case "crosspost":
+		return m.crosspostCommand(args, confirmCrosspost)
+	case "archive":
+		return m.archiveCommand(true, confirmArchive)
+	case "unarchive":
+		return m.archiveCommand(false, false)
+	case "rejoin":
+		return m.rejoin()
+	case "switch":
+		m.repick = true
+		return tea.Quit
+	case "saved":
+		m.setStatus("loading saved items…")
+		return listSaved(m.client, true)
+	default:
+		m.setStatus(fmt.Sprintf("unknown command /%s", name))
+		return nil
+	}
+}
+
+func (m *model) setNotifyLevel(level, status string) tea.Cmd {
+	if err := m.config.setNotifyLevel(m.channelID, level); err != nil {
+		m.logError(fmt.Errorf("could not save notification settings: %w", err))
+		return nil
+	}
+
+	m.setStatus(status)
+	return nil
+}
+
+func joinChannel(client *SlackClient, ref string) tea.Cmd {
+	return func() tea.Msg {
+		channelID, err := client.ResolveChannel(ref)
+		if err != nil {
+			return channelSwitchMsg{err: err}
+		}
+
+		// Fall back to the ID if the channel info isn't available
+		channelName := channelID
+		channel, err := client.ChannelInfo(channelID)
+		if err == nil {
+			channelName = client.ChannelDisplayName(channel)
+		} else {
+			channel = nil
+		}
+
+		return channelSwitchMsg{channelID: channelID, channelName: channelName, channel: channel}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestIsCommand(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  bool
+	}{
+		{"/join #general", true},
+		{"  /saved", true},
+		{"/etc/hosts is wrong", false},
+		{"/shrug", false},
+		{"//join #general", false},
+		{"join #general", false},
+	} {
+		if got := isCommand(tc.input); got != tc.want {
+			t.Errorf("isCommand(%q) = %v, want %v", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestUnescapeCommand(t *testing.T) {
+	for _, tc := range []struct {
+		text, want string
+	}{
+		{"//join #general", "/join #general"},
+		{"//server/share", "//server/share"},
+		{"/shrug", "/shrug"},
+	} {
+		if got := unescapeCommand(tc.text); got != tc.want {
+			t.Errorf("unescapeCommand(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How long after the last keystroke we're no longer considered typing
+const composePause = 2 * time.Second
+
+type composeIdleMsg struct{}
+
+// composing reports whether redraws should wait because there's unsent
+// text that was typed recently.
+func (m *model) composing() bool {
+	return m.config.PauseWhileTyping &&
+		m.input.Value() != "" &&
+		time.Since(m.lastKeystroke) < composePause
+}
+
+// redraw updates the viewport, or defers it until we stop typing.
+func (m *model) redraw() tea.Cmd {
+	if !m.composing() {
+		m.updateViewportContent()
+		return nil
+	}
+
+	if m.needsRedraw {
+		return nil
+	}
+	m.needsRedraw = true
+	return waitComposeIdle(composePause - time.Since(m.lastKeystroke))
+}
+
+// composeIdle applies a deferred redraw once we've stopped typing, or
+// checks again later when we haven't.
+func (m *model) composeIdle() tea.Cmd {
+	if m.needsRedraw && m.composing() {
+		return waitComposeIdle(composePause - time.Since(m.lastKeystroke))
+	}
+
+	m.flushRedraw()
+	return nil
+}
+
+// flushRedraw applies a deferred redraw right away.
+func (m *model) flushRedraw() {
+	if !m.needsRedraw {
+		return
+	}
+
+	m.needsRedraw = false
+	m.updateViewportContent()
+}
+
+func waitComposeIdle(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return composeIdleMsg{}
+	})
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Longer prompts leave too little room to type
+const maxPromptLength = 16
+
+// Slack doesn't return more messages per page than this
+const maxFetchLimit = 999
+
+// Config holds user preferences that persist between sessions.
+type Config struct {
+	ShowTimestamps bool `json:"show_timestamps"`
+
+	// TimestampPosition puts timestamps on the "left" (the default) or
+	// "right" of messages, or hides them with "none".
+	TimestampPosition string `json:"timestamp_position"`
+
+	// BotPrefixes maps a bot ID, app ID or bot name to a short prefix
+	// (usually an emoji) rendered before that bot's messages.
+	BotPrefixes map[string]string `json:"bot_prefixes,omitempty"`
+
+	// MentionBell rings the terminal bell when a mention or DM arrives.
+	MentionBell bool `json:"mention_bell"`
+
+	// MentionSound is a sound file played instead of the bell, if set.
+	MentionSound string `json:"mention_sound,omitempty"`
+
+	// ChannelNotifications maps a channel ID to its notification level:
+	// "all", "mentions" (the default) or "none".
+	ChannelNotifications map[string]string `json:"channel_notifications,omitempty"`
+
+	// VimMode makes Esc switch from the input to selecting messages (normal
+	// mode) instead of quitting.
+	VimMode bool `json:"vim_mode"`
+
+	// Prompt is shown before the input, in PromptColor (an ANSI color
+	// number or a hex code).
+	Prompt      string `json:"prompt"`
+	PromptColor string `json:"prompt_color,omitempty"`
+
+	// UsernameWidth cuts longer names to this many characters and pads
+	// shorter ones, so message text lines up. 0 leaves names as they are.
+	UsernameWidth int `json:"username_width,omitempty"`
+
+	// SelfColor is the color of our own name in messages, as an ANSI color
+	// number or a hex code.
+	SelfColor string `json:"self_color,omitempty"`
+
+	// PostAs sets the username and icon messages are posted with. Only
+	// works with bot tokens.
+	PostAs *Sender `json:"post_as,omitempty"`
+
+	// HistoryPath is the directory sent messages are kept in, by default
+	// $XDG_STATE_HOME/slkops/history.
+	HistoryPath string `json:"history_path,omitempty"`
+
+	// HistoryFormat is "per_channel" (the default) for a file per channel,
+	// or "single" for one file shared by all of them.
+	HistoryFormat string `json:"history_format,omitempty"`
+
+	// AckEmoji is the reaction added to the latest message with the ack
+	// key, by its Slack name (default "+1").
+	AckEmoji string `json:"ack_emoji,omitempty"`
+
+	// DoneEmoji is the reaction marking a message as handled. When set, the
+	// header counts the loaded messages without it.
+	DoneEmoji string `json:"done_emoji,omitempty"`
+
+	// RefreshIndicator shows a dot below the input that blinks on every
+	// poll.
+	RefreshIndicator bool `json:"refresh_indicator"`
+
+	// LinkPreviews shows the title and description of linked pages below
+	// messages, as unfurled by Slack.
+	LinkPreviews bool `json:"link_previews"`
+
+	// Scrollbar shows where the messages in view are, on the right edge.
+	Scrollbar bool `json:"scrollbar"`
+
+	// CoalesceJoins shows this many or more consecutive join and leave
+	// messages as a single line until expanded. 0 shows each of them.
+	CoalesceJoins int `json:"coalesce_joins"`
+
+	// RequestTimeout and DialTimeout are how long, in seconds, a request
+	// to Slack and connecting to it may take. KeepAlive is how often idle
+	// connections are probed, 0 turns that off.
+	RequestTimeout int `json:"request_timeout"`
+	DialTimeout    int `json:"dial_timeout"`
+	KeepAlive      int `json:"keepalive"`
+
+	// FetchLimit is how many messages are fetched at once, the latest ones
+	// when opening a channel or a page of the new ones when polling.
+	FetchLimit int `json:"fetch_limit"`
+
+	// CollapseLines collapses messages longer than this many lines to a
+	// preview until expanded. 0 shows every message in full.
+	CollapseLines int `json:"collapse_lines"`
+
+	// PauseWhileTyping holds back redraws for new messages while there's
+	// unsent text in the input, until typing stops for a moment.
+	PauseWhileTyping bool `json:"pause_while_typing"`
+
+	// RecentEmoji are the emoji last reacted with in the picker, most
+	// recent first.
+	RecentEmoji []string `json:"recent_emoji,omitempty"`
+
+	// ConfirmLines and ConfirmChars ask to press Enter again before sending
+	// messages with more lines or characters than these, against flooding
+	// the channel with an accidental paste. 0 disables either check.
+	ConfirmLines int `json:"confirm_lines"`
+	ConfirmChars int `json:"confirm_chars"`
+
+	// LocalPins are the timestamps of the messages pinned to the top of the
+	// view, by channel ID. Only we see them, unlike Slack pins.
+	LocalPins map[string][]string `json:"local_pins,omitempty"`
+
+	// ChannelThemes recolor the UI while a channel is open, by channel ID
+	// or name.
+	ChannelThemes map[string]ChannelTheme `json:"channel_themes,omitempty"`
+
+	// Translate shows messages translated to our language below them.
+	Translate *TranslateConfig `json:"translate,omitempty"`
+
+	// KeywordStyles color keywords in messages, e.g. ERROR in red.
+	KeywordStyles []KeywordStyle `json:"keyword_styles,omitempty"`
+
+	// RedactPatterns are regular expressions masked with --redact, on top
+	// of the default ones for common secrets and email addresses.
+	RedactPatterns []string `json:"redact_patterns,omitempty"`
+
+	// Keys remaps actions to keys, e.g. {"quit": ["ctrl+q"]}.
+	Keys map[string][]string `json:"keys,omitempty"`
+
+	path string
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		ShowTimestamps:    true,
+		TimestampPosition: timestampLeft,
+		MentionBell:       true,
+		Prompt:            "➤ ",
+		PromptColor:       "62",
+		SelfColor:         "36",
+		AckEmoji:          "+1",
+		CollapseLines:     20,
+		FetchLimit:        20,
+		CoalesceJoins:     3,
+		RequestTimeout:    int(requestTimeout / time.Second),
+		DialTimeout:       int(dialTimeout / time.Second),
+		KeepAlive:         int(keepAlive / time.Second),
+		LinkPreviews:      true,
+		ConfirmLines:      30,
+		ConfirmChars:      2000,
+	}
+}
+
+func configPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(configHome, "slkops", "config.json"), nil
+}
+
+// loadConfig reads the config file, falling back to defaults for any
+// setting that is missing or when the file does not exist yet.
+func loadConfig() (*Config, error) {
+	cfg := defaultConfig()
+
+	p, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg.path = p
+
+	content, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, cfg); err != nil {
+		return nil, err
+	}
+
+	switch cfg.TimestampPosition {
+	case timestampLeft, timestampRight, timestampNone:
+	default:
+		return nil, fmt.Errorf("timestamp_position must be left, right or none, not %q", cfg.TimestampPosition)
+	}
+
+	if cfg.FetchLimit < 1 || cfg.FetchLimit > maxFetchLimit {
+		return nil, fmt.Errorf("fetch_limit must be between 1 and %d, not %d", maxFetchLimit, cfg.FetchLimit)
+	}
+
+	if cfg.RequestTimeout < 1 || cfg.DialTimeout < 1 || cfg.KeepAlive < 0 {
+		return nil, errors.New("request_timeout and dial_timeout must be at least 1 second, and keepalive can't be negative")
+	}
+
+	if err := checkChannelThemes(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Translate != nil && (cfg.Translate.Endpoint == "" || cfg.Translate.Language == "") {
+		return nil, errors.New("translate needs an endpoint and a language")
+	}
+
+	if n := utf8.RuneCountInString(cfg.Prompt); n > maxPromptLength {
+		return nil, fmt.Errorf("prompt is %d characters long, the maximum is %d", n, maxPromptLength)
+	}
+
+	return cfg, nil
+}
+
+// botPrefix returns the configured prefix for a bot or integration
+// message, or an empty string when the message isn't from a mapped bot.
+func (c *Config) botPrefix(msg Message) string {
+	if msg.BotID == "" && msg.AppID == "" {
+		return ""
+	}
+
+	keys := []string{msg.BotID, msg.AppID, msg.Username}
+	if msg.BotProfile != nil {
+		keys = append(keys, msg.BotProfile.Name)
+	}
+
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if prefix, ok := c.BotPrefixes[key]; ok {
+			return prefix
+		}
+	}
+
+	return ""
+}
+
+// applyPrompt sets the configured prompt on an input.
+func (c *Config) applyPrompt(ti *textinput.Model) {
+	ti.Prompt = c.Prompt
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(c.PromptColor))
+}
+
+func (c *Config) notifyLevel(channelID string) string {
+	if level, ok := c.ChannelNotifications[channelID]; ok {
+		return level
+	}
+	return notifyMentions
+}
+
+func (c *Config) setNotifyLevel(channelID, level string) error {
+	if level == notifyMentions {
+		delete(c.ChannelNotifications, channelID)
+	} else {
+		if c.ChannelNotifications == nil {
+			c.ChannelNotifications = make(map[string]string)
+		}
+		c.ChannelNotifications[channelID] = level
+	}
+
+	return c.save()
+}
+
+func (c *Config) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	bs, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, bs, 0644)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Crossposting to more channels than this needs the command run twice
+const crosspostConfirmChannels = 3
+
+// Pause between posts, to stay under the chat.postMessage rate limit
+const crosspostDelay = time.Second
+
+type crosspostResult struct {
+	channel string
+	err     error
+}
+
+type crosspostMsg struct {
+	results []crosspostResult
+}
+
+// parseCrosspost splits "#a #b C0123ABCD text" into the channels and the
+// message.
+func parseCrosspost(args string) ([]string, string) {
+	var channels []string
+	rest := strings.TrimSpace(args)
+	for rest != "" {
+		field, remaining, _ := strings.Cut(rest, " ")
+		if !strings.HasPrefix(field, "#") && !channelIDPattern.MatchString(field) {
+			break
+		}
+		channels = append(channels, field)
+		rest = strings.TrimSpace(remaining)
+	}
+
+	return channels, rest
+}
+
+// crosspostCommand handles "/crosspost #a #b <text>", posting text to each
+// channel. Posting to many channels needs the command run twice in a row.
+func (m *model) crosspostCommand(args string, confirmed bool) tea.Cmd {
+	channels, text := parseCrosspost(args)
+	if len(channels) == 0 || text == "" {
+		m.setStatus("usage: /crosspost <#channel>... <text>")
+		return nil
+	}
+
+	if len(channels) > crosspostConfirmChannels && !confirmed {
+		m.pendingCrosspost = args
+		m.setStatus(fmt.Sprintf("run the same /crosspost again to post to %d channels", len(channels)))
+		return nil
+	}
+
+	var as Sender
+	if m.config.PostAs != nil {
+		as = *m.config.PostAs
+	}
+
+	m.setStatus(fmt.Sprintf("posting to %d channels…", len(channels)))
+	client := m.client
+	return func() tea.Msg {
+		results := make([]crosspostResult, 0, len(channels))
+		for i, ref := range channels {
+			if i > 0 {
+				time.Sleep(crosspostDelay)
+			}
+
+			channelID, err := client.ResolveChannel(ref)
+			if err == nil {
+				_, err = client.SendMessageAs(channelID, text, as)
+			}
+			results = append(results, crosspostResult{ref, err})
+		}
+		return crosspostMsg{results}
+	}
+}
+
+func (m *model) crossposted(msg crosspostMsg) {
+	posted := 0
+	for _, result := range msg.results {
+		if result.err != nil {
+			m.logError(fmt.Errorf("could not post to %s: %w", result.channel, result.err))
+			continue
+		}
+		posted++
+	}
+
+	status := fmt.Sprintf("posted to %d of %d channels", posted, len(msg.results))
+	if posted < len(msg.results) {
+		status += fmt.Sprintf(" (%s shows the errors)", m.keys.ErrorLog.Help().Key)
+	}
+	m.setStatus(status)
+}
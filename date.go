@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Messages loaded from the day /date jumps to, and from before it for
+// context
+const (
+	dateDayLimit     = 100
+	dateContextLimit = 10
+)
+
+type dateMsg struct {
+	channelID string
+	day       time.Time
+	messages  []Message
+	err       error
+}
+
+// dateCommand handles "/date YYYY-MM-DD", loading the messages of that day
+// and jumping to the first one.
+func (m *model) dateCommand(args string) tea.Cmd {
+	day, err := time.ParseInLocation("2006-01-02", args, time.Local)
+	if err != nil {
+		m.setStatus("usage: /date YYYY-MM-DD")
+		return nil
+	}
+
+	m.setStatus(fmt.Sprintf("loading messages from %s…", args))
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		start := strconv.FormatInt(day.Unix(), 10)
+		end := strconv.FormatInt(day.AddDate(0, 0, 1).Unix(), 10)
+
+		messages, err := client.HistoryBetween(channelID, start, end, dateDayLimit)
+		if err != nil {
+			return dateMsg{err: err}
+		}
+		before, err := client.HistoryBetween(channelID, "", start, dateContextLimit)
+		if err != nil {
+			return dateMsg{err: err}
+		}
+
+		return dateMsg{channelID, day, append(messages, before...), nil}
+	}
+}
+
+// jumpToDate adds the messages loaded by /date and selects the first one
+// posted on that day, or the closest one after it.
+func (m *model) jumpToDate(msg dateMsg) tea.Cmd {
+	if msg.err != nil {
+		m.logError(fmt.Errorf("could not load the messages: %w", msg.err))
+		return nil
+	}
+	if msg.channelID != m.channelID {
+		return nil
+	}
+
+	for _, message := range msg.messages {
+		m.addMessage(message)
+	}
+	m.sortMessages()
+	m.updateViewportContent()
+
+	for i := range m.messages {
+		if m.messages[i].timestamp.Before(msg.day) || !m.visible(i) {
+			continue
+		}
+		if !m.selecting {
+			m.startSelection()
+		}
+		if !m.messages[i].timestamp.Before(msg.day.AddDate(0, 0, 1)) {
+			m.setStatus(fmt.Sprintf("no messages on %s, showing the next one", msg.day.Format("2006-01-02")))
+		}
+		return m.jumpTo(i)
+	}
+
+	m.setStatus(fmt.Sprintf("no messages on or after %s", msg.day.Format("2006-01-02")))
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How often the DND status is refreshed, as it can change from other
+// clients or on a schedule
+const dndRefreshInterval = 5 * time.Minute
+
+type dndRefreshMsg struct{}
+
+type dndMsg struct {
+	info      *DndInfo
+	status    string // shown once the status has been changed
+	err       error
+	scheduled bool // from the periodic refresh, which schedules the next
+}
+
+func dndRefresh(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return dndRefreshMsg{}
+	})
+}
+
+func fetchDnd(client *SlackClient) tea.Cmd {
+	return func() tea.Msg {
+		info, err := client.DndInfo()
+		return dndMsg{info: info, err: err, scheduled: true}
+	}
+}
+
+func (m *model) updateDnd(msg dndMsg) {
+	switch {
+	case msg.err != nil && !msg.scheduled:
+		m.logError(fmt.Errorf("could not update Do Not Disturb: %w", msg.err))
+		return
+	case msg.err != nil:
+		// Once is enough, it's refreshed every few minutes
+		if !m.dndReadFails {
+			m.dndReadFails = true
+			m.logError(fmt.Errorf("could not read the Do Not Disturb status: %w", msg.err))
+		}
+		return
+	}
+
+	m.dnd = msg.info
+	m.dndReadFails = false
+	if msg.status != "" {
+		m.setStatus(msg.status)
+	}
+}
+
+// dndActive reports whether Slack's Do Not Disturb is on, which silences
+// the mention bell.
+func (m *model) dndActive() bool {
+	return m.dnd.Active(time.Now())
+}
+
+// snooze handles /dnd: "/dnd 30" pauses notifications for 30 minutes and
+// "/dnd off" resumes them.
+func (m *model) snooze(args string) tea.Cmd {
+	client := m.client
+	change, status := client.EndSnooze, "notifications resumed"
+	if args != "off" {
+		minutes, err := strconv.Atoi(args)
+		if err != nil || minutes <= 0 {
+			m.setStatus("usage: /dnd <minutes>|off")
+			return nil
+		}
+
+		change = func() error { return client.SetSnooze(minutes) }
+		status = fmt.Sprintf("notifications paused for %d minutes", minutes)
+	}
+
+	return func() tea.Msg {
+		if err := change(); err != nil {
+			return dndMsg{err: err}
+		}
+		info, err := client.DndInfo()
+		return dndMsg{info: info, status: status, err: err}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorMsg carries the text written in the external editor.
+type editorMsg struct {
+	text    string
+	send    bool     // send straight away instead of loading it into the input
+	snippet *snippet // upload as a snippet instead
+	err     error
+}
+
+func editorCommand() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+// composeInEditor suspends the UI and opens $EDITOR on a temporary file
+// seeded with draft, like git commit does.
+func (m *model) composeInEditor(draft string, send bool) tea.Cmd {
+	return m.editInEditor(draft, ".md", editorMsg{send: send})
+}
+
+// editInEditor opens $EDITOR on a temporary file with extension ext,
+// returning result with the text once it exits.
+func (m *model) editInEditor(draft, ext string, result editorMsg) tea.Cmd {
+	file, err := os.CreateTemp("", "slkops-*"+ext)
+	if err != nil {
+		m.logError(fmt.Errorf("could not create draft file: %w", err))
+		return nil
+	}
+	path := file.Name()
+
+	_, err = file.WriteString(draft)
+	file.Close()
+	if err != nil {
+		os.Remove(path)
+		m.logError(fmt.Errorf("could not write draft file: %w", err))
+		return nil
+	}
+
+	// Run through the shell so $EDITOR can include arguments, e.g. "code -w"
+	cmd := exec.Command("sh", "-c", editorCommand()+` "$1"`, "sh", path)
+	// The terminal itself rather than the program's output, or the
+	// editor's output would be piped
+	cmd.Stdout = os.Stdout
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editorMsg{err: fmt.Errorf("editor failed: %w", err)}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return editorMsg{err: err}
+		}
+
+		result.text = strings.TrimRight(string(content), "\n")
+		return result
+	})
+}
+
+// loadDraft puts text back into the input. Multi-line text doesn't fit in
+// the single line input, so it's staged to be sent as is instead.
+func (m *model) loadDraft(text string) {
+	if strings.Contains(text, "\n") {
+		m.staged = text
+		m.input.Reset()
+		return
+	}
+
+	m.staged = ""
+	m.input.SetValue(text)
+	m.input.CursorEnd()
+}
+
+// draft returns the message being composed.
+func (m *model) draft() string {
+	if m.staged != "" {
+		return m.staged
+	}
+	return m.input.Value()
+}
+
+// isMultilinePaste reports whether a key message is pasted text spanning
+// several lines. Without bracketed paste support pasted text still arrives
+// as a run of runes, so any run containing a newline counts.
+func isMultilinePaste(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyRunes && strings.ContainsAny(string(msg.Runes), "\r\n")
+}
+
+// pasteMultiline stages pasted lines together with what was already typed,
+// so they're sent as a single message with the newlines intact.
+func (m *model) pasteMultiline(msg tea.KeyMsg) {
+	pasted := strings.ReplaceAll(string(msg.Runes), "\r\n", "\n")
+	pasted = strings.ReplaceAll(pasted, "\r", "\n")
+
+	if m.staged != "" {
+		m.staged += "\n" + strings.TrimRight(pasted, "\n")
+		return
+	}
+
+	value := []rune(m.input.Value())
+	pos := m.input.Position()
+	m.loadDraft(strings.TrimRight(string(value[:pos])+pasted+string(value[pos:]), "\n"))
+}
+
+func (m *model) discardStaged() {
+	m.staged = ""
+}
+
+func (m *model) stagedBanner() string {
+	lines := strings.Count(m.staged, "\n") + 1
+	return fmt.Sprintf("✎ %d-line message staged (Enter to send, Esc to discard)", lines)
+}
+
+// confirmLarge asks to press Enter again before sending a message over the
+// configured size, reporting whether it's good to go.
+func (m *model) confirmLarge(text string) bool {
+	lines := strings.Count(text, "\n") + 1
+	chars := utf8.RuneCountInString(text)
+	large := (m.config.ConfirmLines > 0 && lines > m.config.ConfirmLines) ||
+		(m.config.ConfirmChars > 0 && chars > m.config.ConfirmChars)
+	if !large || m.pendingLarge == text {
+		m.pendingLarge = ""
+		return true
+	}
+
+	m.pendingLarge = text
+	m.setStatus(fmt.Sprintf("this message is %d lines and %d characters long, press Enter again to send it", lines, chars))
+	return false
+}
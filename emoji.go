@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How many recently used emoji are remembered for the top of the picker
+const maxRecentEmoji = 8
+
+var emojiPickerKeys = struct {
+	Up, Down, Pick, Cancel key.Binding
+}{
+	Up:     binding("previous emoji", "up", "ctrl+p"),
+	Down:   binding("next emoji", "down", "ctrl+n"),
+	Pick:   binding("react", "enter"),
+	Cancel: binding("cancel", "esc"),
+}
+
+// standardEmoji are the commonly used standard emoji, by Slack name.
+var standardEmoji = []struct {
+	name  string
+	glyph string
+}{
+	{"+1", "👍"},
+	{"-1", "👎"},
+	{"white_check_mark", "✅"},
+	{"heavy_check_mark", "✔️"},
+	{"x", "❌"},
+	{"eyes", "👀"},
+	{"pray", "🙏"},
+	{"raised_hands", "🙌"},
+	{"clap", "👏"},
+	{"tada", "🎉"},
+	{"rocket", "🚀"},
+	{"fire", "🔥"},
+	{"100", "💯"},
+	{"heart", "❤️"},
+	{"joy", "😂"},
+	{"smile", "😄"},
+	{"slightly_smiling_face", "🙂"},
+	{"wink", "😉"},
+	{"thinking_face", "🤔"},
+	{"sweat_smile", "😅"},
+	{"sob", "😭"},
+	{"scream", "😱"},
+	{"face_palm", "🤦"},
+	{"shrug", "🤷"},
+	{"wave", "👋"},
+	{"ok_hand", "👌"},
+	{"muscle", "💪"},
+	{"point_up", "☝️"},
+	{"thumbsup", "👍"},
+	{"thumbsdown", "👎"},
+	{"warning", "⚠️"},
+	{"rotating_light", "🚨"},
+	{"bug", "🐛"},
+	{"construction", "🚧"},
+	{"hourglass_flowing_sand", "⏳"},
+	{"stopwatch", "⏱️"},
+	{"question", "❓"},
+	{"exclamation", "❗"},
+	{"bulb", "💡"},
+	{"memo", "📝"},
+	{"pushpin", "📌"},
+	{"link", "🔗"},
+	{"lock", "🔒"},
+	{"key", "🔑"},
+	{"wrench", "🔧"},
+	{"hammer_and_wrench", "🛠️"},
+	{"gear", "⚙️"},
+	{"package", "📦"},
+	{"chart_with_upwards_trend", "📈"},
+	{"chart_with_downwards_trend", "📉"},
+	{"red_circle", "🔴"},
+	{"large_green_circle", "🟢"},
+	{"large_yellow_circle", "🟡"},
+	{"star", "⭐"},
+	{"sparkles", "✨"},
+	{"zap", "⚡"},
+	{"boom", "💥"},
+	{"coffee", "☕"},
+	{"beers", "🍻"},
+	{"cake", "🍰"},
+	{"see_no_evil", "🙈"},
+	{"skull", "💀"},
+	{"ghost", "👻"},
+	{"robot_face", "🤖"},
+}
+
+type customEmojiMsg struct {
+	names []string
+	err   error
+}
+
+// emojiPicker searches all the emoji we know of to react to a message.
+type emojiPicker struct {
+	ts      string
+	search  string
+	names   []string
+	matches []string
+	index   int
+}
+
+func emojiGlyph(name string) string {
+	for _, e := range standardEmoji {
+		if e.name == name {
+			return e.glyph
+		}
+	}
+	return ""
+}
+
+// openEmojiPicker shows the picker for the selected message, loading the
+// custom emoji in the background.
+func (m *model) openEmojiPicker() tea.Cmd {
+	selected, ok := m.selectedMessage()
+	if !ok || selected.message.Ts == "" {
+		return nil
+	}
+
+	m.emojiPicker = &emojiPicker{ts: selected.message.Ts}
+	m.setEmojiNames(nil)
+	m.openOverlay("React with", m.emojiPicker.view(m.viewport.Height))
+
+	client := m.client
+	return func() tea.Msg {
+		names, err := client.CustomEmoji()
+		return customEmojiMsg{names, err}
+	}
+}
+
+// setEmojiNames lists the recently used emoji first, then the standard
+// ones and then custom.
+func (m *model) setEmojiNames(custom []string) {
+	p := m.emojiPicker
+	seen := map[string]bool{}
+	p.names = p.names[:0]
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			p.names = append(p.names, name)
+		}
+	}
+
+	for _, name := range m.config.RecentEmoji {
+		add(name)
+	}
+	for _, e := range standardEmoji {
+		add(e.name)
+	}
+	for _, name := range custom {
+		add(name)
+	}
+
+	p.filter()
+}
+
+// filter lists the emoji whose name contains the search, those starting
+// with it first.
+func (p *emojiPicker) filter() {
+	var prefixed, contained []string
+	for _, name := range p.names {
+		if strings.HasPrefix(name, p.search) {
+			prefixed = append(prefixed, name)
+		} else if strings.Contains(name, p.search) {
+			contained = append(contained, name)
+		}
+	}
+
+	p.matches = append(prefixed, contained...)
+	p.index = 0
+}
+
+func (p *emojiPicker) view(height int) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Search: %s▏\n\n", p.search))
+
+	if len(p.matches) == 0 {
+		b.WriteString(statusStyle.Render("No matching emoji"))
+		return b.String()
+	}
+
+	// Keep the highlighted emoji in view
+	rows := height - 2
+	if rows < 1 {
+		rows = 10
+	}
+	start := 0
+	if p.index >= rows {
+		start = p.index - rows + 1
+	}
+	end := start + rows
+	if end > len(p.matches) {
+		end = len(p.matches)
+	}
+
+	for i := start; i < end; i++ {
+		label := ":" + p.matches[i] + ":"
+		if glyph := emojiGlyph(p.matches[i]); glyph != "" {
+			label = glyph + " " + label
+		}
+		if i == p.index {
+			b.WriteString(selectedStyle.Render("▶ "+label) + "\n")
+		} else {
+			b.WriteString("  " + label + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// handleEmojiKey searches and picks while the emoji picker is open.
+func (m *model) handleEmojiKey(msg tea.KeyMsg) tea.Cmd {
+	p := m.emojiPicker
+
+	switch {
+	case key.Matches(msg, emojiPickerKeys.Cancel):
+		m.closeEmojiPicker()
+		return nil
+	case key.Matches(msg, emojiPickerKeys.Pick):
+		if p.index >= len(p.matches) {
+			return nil
+		}
+		name := p.matches[p.index]
+		m.closeEmojiPicker()
+		m.useEmoji(name)
+		return react(m.client, m.channelID, p.ts, name)
+	case key.Matches(msg, emojiPickerKeys.Up):
+		if p.index > 0 {
+			p.index--
+		}
+	case key.Matches(msg, emojiPickerKeys.Down):
+		if p.index < len(p.matches)-1 {
+			p.index++
+		}
+	case msg.Type == tea.KeyBackspace:
+		if p.search != "" {
+			runes := []rune(p.search)
+			p.search = string(runes[:len(runes)-1])
+			p.filter()
+		}
+	case msg.Type == tea.KeyRunes:
+		p.search += strings.ToLower(string(msg.Runes))
+		p.filter()
+	}
+
+	m.refreshEmojiPicker()
+	return nil
+}
+
+func (m *model) refreshEmojiPicker() {
+	if m.emojiPicker == nil || m.overlay == nil {
+		return
+	}
+	m.overlay.content = m.emojiPicker.view(m.viewport.Height)
+	m.viewport.SetContent(m.overlay.content)
+}
+
+func (m *model) closeEmojiPicker() {
+	m.emojiPicker = nil
+	if m.overlay != nil {
+		m.closeOverlay()
+	}
+}
+
+// useEmoji moves name to the top of the recently used emoji.
+func (m *model) useEmoji(name string) {
+	recent := []string{name}
+	for _, r := range m.config.RecentEmoji {
+		if r != name && len(recent) < maxRecentEmoji {
+			recent = append(recent, r)
+		}
+	}
+	m.config.RecentEmoji = recent
+
+	if err := m.config.save(); err != nil {
+		m.logError(fmt.Errorf("could not save the recently used emoji: %w", err))
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Oldest errors are dropped past this many
+const maxErrorLog = 200
+
+// errEntry is an error kept in the session's error log.
+type errEntry struct {
+	time time.Time
+	err  error
+}
+
+// logError records an error for the error log, showing it briefly in the
+// header.
+func (m *model) logError(err error) {
+	m.errorLog = append(m.errorLog, errEntry{time.Now(), err})
+	if len(m.errorLog) > maxErrorLog {
+		m.errorLog = m.errorLog[len(m.errorLog)-maxErrorLog:]
+	}
+	m.setStatus(err.Error())
+}
+
+// errorLogText lists the logged errors, newest first, wrapped to the
+// viewport width.
+func (m *model) errorLogText() string {
+	if len(m.errorLog) == 0 {
+		return "No errors so far."
+	}
+
+	wrap := lipgloss.NewStyle().Width(m.viewport.Width)
+	var out strings.Builder
+	for i := len(m.errorLog) - 1; i >= 0; i-- {
+		entry := m.errorLog[i]
+		line := timeStyle.Render(entry.time.Format("15:04:05")) + " " + errorStyle.Render(entry.err.Error())
+		out.WriteString(wrap.Render(line) + "\n")
+	}
+
+	return out.String()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Pause between thread fetches, to stay under the conversations.replies
+// rate limit on channels with many threads
+const exportThreadDelay = time.Second
+
+// Pause between permalink lookups, which have a much higher rate limit
+const exportPermalinkDelay = 100 * time.Millisecond
+
+// Exports use ISO 8601 times with the UTC offset, so they sort and parse
+// the same wherever they're read
+const exportTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+type exportMsg struct {
+	path  string
+	count int
+	err   error
+}
+
+type exportedMessage struct {
+	Ts        string            `json:"ts"`
+	Time      string            `json:"time"`
+	User      string            `json:"user"`
+	Text      string            `json:"text"`
+	Permalink string            `json:"permalink,omitempty"`
+	Replies   []exportedMessage `json:"replies,omitempty"`
+}
+
+// exportCommand handles "/export [--include-threads] [--utc] [--permalinks]
+// [file]", writing the loaded messages to file as JSON, Markdown or plain
+// text depending on its extension. Times are local unless --utc is given.
+// --permalinks links each message back to Slack.
+func (m *model) exportCommand(args string) tea.Cmd {
+	includeThreads, permalinks := false, false
+	loc := time.Local
+	path := ""
+	for _, arg := range strings.Fields(args) {
+		switch arg {
+		case "--include-threads":
+			includeThreads = true
+		case "--utc":
+			loc = time.UTC
+		case "--permalinks":
+			permalinks = true
+		default:
+			path = arg
+		}
+	}
+	if path == "" {
+		path = fmt.Sprintf("slkops-%s-%s.txt", m.channelName, time.Now().Format("20060102-150405"))
+	}
+
+	var messages []Message
+	for _, msg := range m.messages {
+		// Still being sent
+		if msg.message.Ts == "" {
+			continue
+		}
+		messages = append(messages, msg.message)
+	}
+
+	m.setStatus(fmt.Sprintf("exporting %d messages…", len(messages)))
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		exported, err := exportMessages(client, channelID, messages, includeThreads, loc)
+		if err != nil {
+			return exportMsg{err: err}
+		}
+		if permalinks {
+			if err := exportPermalinks(client, channelID, exported); err != nil {
+				return exportMsg{err: err}
+			}
+		}
+
+		var content []byte
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			content, err = json.MarshalIndent(exported, "", "  ")
+			if err != nil {
+				return exportMsg{err: err}
+			}
+		case ".md":
+			var footnotes []string
+			content = []byte(exportMarkdown(exported, 0, &footnotes))
+			if len(footnotes) > 0 {
+				content = append(content, "\n"+strings.Join(footnotes, "\n")+"\n"...)
+			}
+		default:
+			content = []byte(exportText(exported, 0))
+		}
+
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return exportMsg{err: err}
+		}
+		return exportMsg{path: path, count: len(exported)}
+	}
+}
+
+// exportMessages resolves the authors of messages and, if includeThreads
+// is set, fetches the replies of those starting a thread.
+func exportMessages(client *SlackClient, channelID string, messages []Message, includeThreads bool, loc *time.Location) ([]exportedMessage, error) {
+	exported := make([]exportedMessage, 0, len(messages))
+	fetched := 0
+	for _, msg := range messages {
+		e := exportMessage(client, msg, loc)
+
+		if includeThreads && msg.ReplyCount > 0 {
+			if fetched > 0 {
+				time.Sleep(exportThreadDelay)
+			}
+			fetched++
+
+			replies, err := client.Replies(channelID, msg.Ts)
+			if err != nil {
+				return nil, fmt.Errorf("could not fetch the thread of %s: %w", msg.Ts, err)
+			}
+			for _, reply := range replies {
+				// The parent comes first
+				if reply.Ts == msg.Ts {
+					continue
+				}
+				e.Replies = append(e.Replies, exportMessage(client, reply, loc))
+			}
+		}
+
+		exported = append(exported, e)
+	}
+
+	return exported, nil
+}
+
+func exportMessage(client *SlackClient, msg Message, loc *time.Location) exportedMessage {
+	username, err := client.UsernameForMessage(msg)
+	if err != nil {
+		username = "unknown"
+	}
+
+	return exportedMessage{
+		Ts:   msg.Ts,
+		Time: parseTs(msg.Ts).In(loc).Format(exportTimeFormat),
+		User: username,
+		Text: msg.Text,
+	}
+}
+
+// exportPermalinks looks up the permalink of every exported message,
+// replies included.
+func exportPermalinks(client *SlackClient, channelID string, messages []exportedMessage) error {
+	for i := range messages {
+		time.Sleep(exportPermalinkDelay)
+
+		link, err := client.Permalink(channelID, messages[i].Ts)
+		if err != nil {
+			return fmt.Errorf("could not get the permalink of %s: %w", messages[i].Ts, err)
+		}
+		messages[i].Permalink = link
+
+		if err := exportPermalinks(client, channelID, messages[i].Replies); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exportText(messages []exportedMessage, depth int) string {
+	indent := strings.Repeat("    ", depth)
+
+	var out strings.Builder
+	for _, msg := range messages {
+		text := strings.ReplaceAll(msg.Text, "\n", "\n"+indent+"  ")
+		out.WriteString(fmt.Sprintf("%s%s %s: %s\n", indent, msg.Time, msg.User, text))
+		out.WriteString(exportText(msg.Replies, depth+1))
+	}
+
+	return out.String()
+}
+
+// exportMarkdown renders messages as a nested list. Permalinks are added
+// to footnotes, referenced after each message.
+func exportMarkdown(messages []exportedMessage, depth int, footnotes *[]string) string {
+	indent := strings.Repeat("  ", depth)
+
+	var out strings.Builder
+	for _, msg := range messages {
+		text := strings.ReplaceAll(msg.Text, "\n", "\n"+indent+"  ")
+		if msg.Permalink != "" {
+			*footnotes = append(*footnotes, fmt.Sprintf("[^%d]: %s", len(*footnotes)+1, msg.Permalink))
+			text += fmt.Sprintf("[^%d]", len(*footnotes))
+		}
+		out.WriteString(fmt.Sprintf("%s- **%s** (%s): %s\n", indent, msg.User, msg.Time, text))
+		out.WriteString(exportMarkdown(msg.Replies, depth+1, footnotes))
+	}
+
+	return out.String()
+}
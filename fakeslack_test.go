@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// slackHandler answers a Slack API method with the value to encode as the
+// response, given the query parameters and the request body.
+type slackHandler func(params url.Values, body []byte) any
+
+// fakeSlack is a Slack API server with canned responses, for testing the
+// client's real HTTP paths.
+type fakeSlack struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]slackHandler
+	calls    map[string][]url.Values
+}
+
+// newFakeSlack starts a fake Slack server and returns a client talking to
+// it. Methods without a handler fail with unknown_method, like Slack's.
+func newFakeSlack(t *testing.T) (*fakeSlack, *SlackClient) {
+	t.Helper()
+
+	fake := &fakeSlack{
+		handlers: make(map[string]slackHandler),
+		calls:    make(map[string][]url.Values),
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(fake.Close)
+
+	client, err := Null("test", http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(client.cachePath) })
+	client.log = log.New(io.Discard, "", 0)
+	if err := client.WithBaseURL(fake.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	return fake, client
+}
+
+func (f *fakeSlack) serve(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	f.calls[method] = append(f.calls[method], r.URL.Query())
+	handler, ok := f.handlers[method]
+	f.mu.Unlock()
+
+	var response any = slackError("unknown_method")
+	if ok {
+		response = handler(r.URL.Query(), body)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handle sets the handler of a method.
+func (f *fakeSlack) handle(method string, handler slackHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[method] = handler
+}
+
+// respond answers a method with the same response every time.
+func (f *fakeSlack) respond(method string, response any) {
+	f.handle(method, func(url.Values, []byte) any { return response })
+}
+
+// callsTo returns the query parameters of every call made to a method.
+func (f *fakeSlack) callsTo(method string) []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// ok is a successful response with the given fields.
+func ok(fields map[string]any) map[string]any {
+	response := map[string]any{"ok": true}
+	for k, v := range fields {
+		response[k] = v
+	}
+	return response
+}
+
+// slackError is the envelope Slack answers failed calls with.
+func slackError(code string, warnings ...string) map[string]any {
+	response := map[string]any{"ok": false, "error": code}
+	if len(warnings) > 0 {
+		response["response_metadata"] = map[string]any{"warnings": warnings}
+	}
+	return response
+}
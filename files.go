@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Number of snippet lines previewed beneath a message
+const filePreviewLines = 5
+
+var filePreviewStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("245")).
+	PaddingLeft(2)
+
+type fileContentMsg struct {
+	file    File
+	content []byte
+	err     error
+}
+
+func fetchFileContent(client *SlackClient, file File) tea.Cmd {
+	return func() tea.Msg {
+		content, err := client.FetchFileContent(file.ID)
+		return fileContentMsg{file, content, err}
+	}
+}
+
+// renderFiles renders the files attached to a message: a short preview for
+// text snippets, and just the type and size for anything else.
+func (m *model) renderFiles(files []File) string {
+	var out strings.Builder
+	for _, f := range files {
+		name := f.Title
+		if name == "" {
+			name = f.Name
+		}
+
+		out.WriteString("\n" + filePreviewStyle.Render(fmt.Sprintf("📎 %s (%s, %s)", name, f.Mimetype, formatSize(f.Size))))
+
+		if !f.IsText() || f.Preview == "" {
+			continue
+		}
+
+		lines := strings.Split(f.Preview, "\n")
+		truncated := f.PreviewIsTruncated
+		if len(lines) > filePreviewLines {
+			lines = lines[:filePreviewLines]
+			truncated = true
+		}
+		if truncated {
+			lines = append(lines, fmt.Sprintf("… (%s to view the full file)", m.keys.OpenFile.Help().Key))
+		}
+
+		out.WriteString("\n" + filePreviewStyle.Render(strings.Join(lines, "\n")))
+	}
+
+	return out.String()
+}
+
+// latestTextFile returns the most recently shared text file in the loaded
+// messages.
+func (m *model) latestTextFile() (File, bool) {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		files := m.messages[i].message.Files
+		for j := len(files) - 1; j >= 0; j-- {
+			if files[j].IsText() {
+				return files[j], true
+			}
+		}
+	}
+
+	return File{}, false
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var highlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("220"))
+
+// visible reports whether the message at index i passes the active filters.
+// Hidden messages are still stored, just not rendered.
+func (m *model) visible(i int) bool {
+	msg := m.messages[i]
+	if m.coalesced(i) {
+		return false
+	}
+	if m.unhandledOnly && m.handled[msg.message.Ts] {
+		return false
+	}
+	if m.reactFilter != "" && !matchesReactFilter(msg.message, m.reactFilter) {
+		return false
+	}
+
+	if m.filter == "" {
+		return true
+	}
+
+	term := strings.ToLower(m.filter)
+	return strings.Contains(strings.ToLower(msg.message.Text), term) ||
+		strings.Contains(strings.ToLower(msg.username), term)
+}
+
+// matchesReactFilter reports whether msg has the reaction in filter, or
+// lacks it when filter starts with '!'.
+func matchesReactFilter(msg Message, filter string) bool {
+	name, negate := strings.CutPrefix(filter, "!")
+	has := false
+	for _, reaction := range msg.Reactions {
+		// Skin tones count as the same reaction, e.g. +1::skin-tone-2
+		if base, _, _ := strings.Cut(reaction.Name, "::"); base == name {
+			has = true
+			break
+		}
+	}
+
+	return has != negate
+}
+
+// highlightPattern matches every case-insensitive occurrence of term, or
+// nothing for an empty term.
+func highlightPattern(term string) *regexp.Regexp {
+	if term == "" {
+		return nil
+	}
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+}
+
+// highlight marks every match of re in text.
+func highlight(text string, re *regexp.Regexp) string {
+	if re == nil {
+		return text
+	}
+
+	return re.ReplaceAllStringFunc(text, func(match string) string {
+		return highlightStyle.Render(match)
+	})
+}
+
+// setFilter restricts the view to messages matching term, or shows all
+// messages again when term is empty.
+func (m *model) setFilter(term string) {
+	m.filter = term
+	m.filterRe = highlightPattern(term)
+	m.filtersChanged()
+}
+
+// setReactFilter restricts the view to messages with a reaction, e.g.
+// "white_check_mark", or without it, e.g. "!white_check_mark". Emoji can be
+// given with or without colons.
+func (m *model) setReactFilter(filter string) {
+	negate := strings.HasPrefix(filter, "!")
+	filter = strings.Trim(strings.TrimPrefix(filter, "!"), ":")
+	if negate && filter != "" {
+		filter = "!" + filter
+	}
+
+	m.reactFilter = filter
+	m.filtersChanged()
+}
+
+func (m *model) filtersChanged() {
+	if m.selecting {
+		m.moveSelection(0)
+		return
+	}
+	m.updateViewportContent()
+	m.viewport.GotoBottom()
+}
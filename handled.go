@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var handledStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+// handledPath is the file the messages we marked handled in a channel are
+// kept in, a timestamp per line. Unlike local pins they pile up, so they
+// live in the state directory rather than in the config.
+func handledPath(team, channelID string) (string, error) {
+	state, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(state, "handled", fmt.Sprintf("%s-%s", team, channelID)), nil
+}
+
+// loadHandled returns the timestamps of the messages marked handled in a
+// channel, none when there's no file yet.
+func loadHandled(team, channelID string) (map[string]bool, error) {
+	handled := make(map[string]bool)
+
+	path, err := handledPath(team, channelID)
+	if err != nil {
+		return handled, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return handled, nil
+	} else if err != nil {
+		return handled, err
+	}
+
+	for _, ts := range strings.Fields(string(content)) {
+		handled[ts] = true
+	}
+	return handled, nil
+}
+
+func saveHandled(team, channelID string, handled map[string]bool) error {
+	path, err := handledPath(team, channelID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	timestamps := make([]string, 0, len(handled))
+	for ts := range handled {
+		timestamps = append(timestamps, ts)
+	}
+	sort.Strings(timestamps)
+
+	content := strings.Join(timestamps, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// loadChannelHandled loads the messages marked handled in the current channel.
+func (m *model) loadChannelHandled() {
+	handled, err := loadHandled(m.client.team, m.channelID)
+	if err != nil {
+		m.logError(fmt.Errorf("could not load the messages marked handled: %w", err))
+	}
+	m.handled = handled
+}
+
+// toggleHandled marks the selected message handled, only for us, or unmarks
+// it.
+func (m *model) toggleHandled() {
+	selected, ok := m.selectedMessage()
+	if !ok || selected.message.Ts == "" {
+		return
+	}
+
+	ts := selected.message.Ts
+	if m.handled[ts] {
+		delete(m.handled, ts)
+		m.setStatus("no longer marked handled")
+	} else {
+		m.handled[ts] = true
+		m.setStatus("marked handled")
+	}
+
+	if err := saveHandled(m.client.team, m.channelID, m.handled); err != nil {
+		m.logError(fmt.Errorf("could not save the messages marked handled: %w", err))
+	}
+
+	if m.unhandledOnly {
+		m.filtersChanged()
+		return
+	}
+	m.updateViewportContent()
+}
+
+// toggleUnhandled handles /unhandled, which shows only the messages not
+// marked handled yet, or everything again.
+func (m *model) toggleUnhandled() {
+	m.unhandledOnly = !m.unhandledOnly
+	if m.unhandledOnly {
+		m.setStatus("showing only the messages not marked handled")
+	} else {
+		m.setStatus("showing all messages")
+	}
+	m.filtersChanged()
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sent message history formats
+const (
+	historyPerChannel = "per_channel"
+	historySingle     = "single"
+)
+
+// Where the history lived before it moved under the XDG state directory
+const legacyHistoryDir = ".slack-chat-history"
+
+// historyStore is where sent messages are kept: a file per channel, or a
+// single file with the team and channel before every message.
+type historyStore struct {
+	dir    string
+	single bool
+}
+
+func checkHistoryFormat(cfg *Config) error {
+	switch cfg.HistoryFormat {
+	case "", historyPerChannel, historySingle:
+		return nil
+	}
+	return fmt.Errorf("unknown history format %q, use %q or %q", cfg.HistoryFormat, historyPerChannel, historySingle)
+}
+
+// stateDir is where slkops keeps what it remembers between sessions,
+// under $XDG_STATE_HOME.
+func stateDir() (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateHome, "slkops"), nil
+}
+
+// newHistoryStore sets up the history directory from the config, moving
+// the history over from the legacy location on first run. The format is
+// expected to be checked already.
+func newHistoryStore(cfg *Config) (historyStore, error) {
+	store := historyStore{single: cfg.HistoryFormat == historySingle}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return store, err
+	}
+
+	if cfg.HistoryPath != "" {
+		store.dir = cfg.HistoryPath
+		if rest, ok := strings.CutPrefix(store.dir, "~/"); ok {
+			store.dir = filepath.Join(home, rest)
+		}
+	} else {
+		state, err := stateDir()
+		if err != nil {
+			return store, err
+		}
+		store.dir = filepath.Join(state, "history")
+
+		legacy := filepath.Join(home, legacyHistoryDir)
+		if err := migrateHistory(legacy, store.dir); err != nil {
+			// Keep using the old location rather than losing the history
+			store.dir = legacy
+		}
+	}
+
+	if err := os.MkdirAll(store.dir, 0755); err != nil {
+		return store, err
+	}
+
+	if store.single {
+		if err := store.consolidate(); err != nil {
+			return store, err
+		}
+	}
+
+	return store, nil
+}
+
+// migrateHistory moves the legacy history directory to dir, unless there's
+// nothing to move or dir already exists.
+func migrateHistory(legacy, dir string) error {
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	return os.Rename(legacy, dir)
+}
+
+// path returns the history file for a channel, and the prefix its lines
+// have in that file.
+func (s historyStore) path(team, channelID string) (string, string) {
+	if s.single {
+		return filepath.Join(s.dir, "history"), team + "\t" + channelID + "\t"
+	}
+	return filepath.Join(s.dir, fmt.Sprintf("%s-%s.history", team, channelID)), ""
+}
+
+// consolidate creates the single history file from the per-channel files
+// the first time it's used. The per-channel files are left in place.
+func (s historyStore) consolidate() error {
+	single, _ := s.path("", "")
+	if _, err := os.Stat(single); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.history"))
+	if err != nil {
+		return err
+	}
+
+	var out strings.Builder
+	for _, file := range files {
+		// Team names may contain dashes, channel IDs don't
+		name := strings.TrimSuffix(filepath.Base(file), ".history")
+		i := strings.LastIndex(name, "-")
+		if i < 0 {
+			continue
+		}
+
+		_, prefix := historyStore{single: true}.path(name[:i], name[i+1:])
+		for _, line := range loadHistory(file, "") {
+			out.WriteString(prefix + line + "\n")
+		}
+	}
+
+	return os.WriteFile(single, []byte(out.String()), 0644)
+}
+
+// loadHistory reads the sent message history from file, keeping the lines
+// with prefix. Returns an empty history if the file doesn't exist yet.
+func loadHistory(historyFile, prefix string) []string {
+	history := []string{}
+	file, err := os.Open(historyFile)
+	if err != nil {
+		return history
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line, ok := strings.CutPrefix(scanner.Text(), prefix); ok {
+			history = append(history, line)
+		}
+	}
+
+	return history
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnwritableHistoryStaysInMemory(t *testing.T) {
+	blocked := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := defaultConfig()
+	config.HistoryPath = filepath.Join(blocked, "history")
+	_, m := newTestModelWithConfig(t, config)
+	if m.historyEnabled {
+		t.Fatal("history enabled in a directory that can't be created")
+	}
+
+	// Nothing is written, not even relative to the working directory
+	cwd := t.TempDir()
+	t.Chdir(cwd)
+	for _, channelID := range []string{"C1", "C2"} {
+		m.switchChannel(channelID, channelID, nil)
+		if err := m.appendToHistory("hello " + channelID); err != nil {
+			t.Fatal(err)
+		}
+		if m.historyFile != "" {
+			t.Errorf("history file %q in use", m.historyFile)
+		}
+	}
+	if entries, _ := os.ReadDir(cwd); len(entries) > 0 {
+		t.Errorf("wrote %s to the working directory", entries[0].Name())
+	}
+	if len(m.history) != 1 || m.history[0] != "hello C2" {
+		t.Errorf("got history %q, want the message sent to C2", m.history)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Subtypes of the messages Slack posts when someone joins or leaves
+var membershipSubtypes = map[string]bool{
+	"channel_join":  true,
+	"group_join":    true,
+	"channel_leave": true,
+	"group_leave":   true,
+}
+
+func isMembershipEvent(msg Message) bool {
+	return membershipSubtypes[msg.Subtype]
+}
+
+// membershipRun returns where the run of consecutive join and leave
+// messages the ith message is part of starts, and how long it is, when it's
+// long enough to be shown as a single line. Filtering shows every message
+// on its own.
+func (m *model) membershipRun(i int) (int, int) {
+	limit := m.config.CoalesceJoins
+	if limit <= 0 || m.filter != "" || m.reactFilter != "" || m.unhandledOnly || !isMembershipEvent(m.messages[i].message) {
+		return i, 0
+	}
+
+	start, end := i, i+1
+	for start > 0 && isMembershipEvent(m.messages[start-1].message) {
+		start--
+	}
+	for end < len(m.messages) && isMembershipEvent(m.messages[end].message) {
+		end++
+	}
+	if end-start < limit {
+		return i, 0
+	}
+	return start, end - start
+}
+
+// coalesced reports whether the ith message is hidden in the line
+// summing up the run of join and leave messages it's part of.
+func (m *model) coalesced(i int) bool {
+	start, n := m.membershipRun(i)
+	return n > 0 && start != i && !m.expanded[m.messages[start].id]
+}
+
+// renderMembershipRun renders the line standing in for a run of join and
+// leave messages starting at the ith one, unless it was expanded.
+func (m *model) renderMembershipRun(i int) (string, bool) {
+	start, n := m.membershipRun(i)
+	if n == 0 || start != i || m.expanded[m.messages[start].id] {
+		return "", false
+	}
+
+	joined, left := 0, 0
+	for _, msg := range m.messages[start : start+n] {
+		if strings.HasSuffix(msg.message.Subtype, "_join") {
+			joined++
+		} else {
+			left++
+		}
+	}
+
+	var parts []string
+	if joined > 0 {
+		parts = append(parts, people(joined)+" joined")
+	}
+	if left > 0 && joined > 0 {
+		parts = append(parts, fmt.Sprintf("%d left", left))
+	} else if left > 0 {
+		parts = append(parts, people(left)+" left")
+	}
+
+	line := statusStyle.Render(fmt.Sprintf("%s (select and press %s to see who)",
+		strings.Join(parts, ", "), m.keys.ToggleExpand.Help().Key))
+	if m.timestampShown(timestampLeft) {
+		line = timeStyle.Render(m.messages[start].timestamp.Format("15:04:05")) + " " + line
+	}
+	return line, true
+}
+
+// toggleMembershipRun shows every message of the selected run of join and
+// leave messages, or sums them up again. Reports whether one was selected.
+func (m *model) toggleMembershipRun() bool {
+	start, n := m.membershipRun(m.selected)
+	if n == 0 {
+		return false
+	}
+
+	if m.expanded == nil {
+		m.expanded = make(map[string]bool)
+	}
+	id := m.messages[start].id
+	m.expanded[id] = !m.expanded[id]
+	m.selected = start
+	m.updateViewportContent()
+	return true
+}
+
+func people(n int) string {
+	if n == 1 {
+		return "1 person"
+	}
+	return fmt.Sprintf("%d people", n)
+}
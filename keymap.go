@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the key bindings for every action. Bindings can be remapped
+// from the config by action name, see inputBindings and selectionBindings.
+type keyMap struct {
+	// While typing in the input
+	Quit             key.Binding
+	Cancel           key.Binding
+	Send             key.Binding
+	HistoryUp        key.Binding
+	HistoryDown      key.Binding
+	EditLast         key.Binding
+	RetrySend        key.Binding
+	Ack              key.Binding
+	ChannelBack      key.Binding
+	ChannelForward   key.Binding
+	Bold             key.Binding
+	Italic           key.Binding
+	NextPane         key.Binding
+	Code             key.Binding
+	Editor           key.Binding
+	OpenFile         key.Binding
+	Select           key.Binding
+	JumpBottom       key.Binding
+	ToggleTimestamps key.Binding
+	PageUp           key.Binding
+	PageDown         key.Binding
+	PrevMessage      key.Binding
+	NextMessage      key.Binding
+	Help             key.Binding
+	ErrorLog         key.Binding
+	TogglePolling    key.Binding
+
+	// While selecting messages
+	Unselect         key.Binding
+	Insert           key.Binding
+	Delete           key.Binding
+	SelectUp         key.Binding
+	SelectDown       key.Binding
+	SelectFirst      key.Binding
+	SelectLast       key.Binding
+	Save             key.Binding
+	OpenSelectedFile key.Binding
+	ReactionPage     key.Binding
+	JumpQuoted       key.Binding
+	OpenThread       key.Binding
+	ShowProfile      key.Binding
+	CopyCode         key.Binding
+	NextReference    key.Binding
+	ToggleExpand     key.Binding
+	CopyRaw          key.Binding
+	React            key.Binding
+	LocalPin         key.Binding
+	MarkHandled      key.Binding
+}
+
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), desc))
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Quit:             binding("quit", "esc", "ctrl+c"),
+		Cancel:           binding("cancel edit or staged message", "esc"),
+		Send:             binding("send message", "enter"),
+		HistoryUp:        binding("previous sent message", "up"),
+		HistoryDown:      binding("next sent message", "down"),
+		EditLast:         binding("edit last sent message", "alt+up"),
+		RetrySend:        binding("retry the last failed send", "ctrl+y"),
+		Ack:              binding("react to the latest message", "alt+a"),
+		ChannelBack:      binding("previous channel", "alt+left"),
+		ChannelForward:   binding("next channel", "alt+right"),
+		Bold:             binding("bold", "ctrl+b"),
+		Italic:           binding("italic", "tab", "alt+i"), // tab is ctrl+i, which moves between panes with --split
+		NextPane:         binding("next pane (with --split)", "tab"),
+		Code:             binding("code", "ctrl+e"),
+		Editor:           binding("compose in $EDITOR", "ctrl+x"),
+		OpenFile:         binding("view latest text file", "ctrl+o"),
+		Select:           binding("select messages", "shift+tab"),
+		JumpBottom:       binding("jump to latest message", "end"),
+		ToggleTimestamps: binding("toggle timestamps", "ctrl+t"),
+		PageUp:           binding("scroll up", "pgup"),
+		PageDown:         binding("scroll down", "pgdown"),
+		PrevMessage:      binding("scroll to previous message", "ctrl+p"),
+		NextMessage:      binding("scroll to next message", "ctrl+n"),
+		Help:             binding("show key bindings", "f1"),
+		ErrorLog:         binding("show errors", "f2"),
+		TogglePolling:    binding("pause or resume polling", "f3"),
+
+		Unselect:         binding("back to the input", "esc", "shift+tab"),
+		SelectUp:         binding("previous message", "up", "k"),
+		SelectDown:       binding("next message", "down", "j"),
+		Insert:           binding("back to typing", "i", "a"),
+		Delete:           binding("delete message (press twice)", "d"),
+		SelectFirst:      binding("first message", "home", "g"),
+		SelectLast:       binding("last message", "end", "G"),
+		Save:             binding("save message for later", "s"),
+		OpenSelectedFile: binding("view text file", "o"),
+		ReactionPage:     binding("more reactions", "R"),
+		JumpQuoted:       binding("open reference or quoted message", "enter"),
+		OpenThread:       binding("show thread", "t"),
+		ShowProfile:      binding("show author's profile", "p"),
+		CopyCode:         binding("copy code block", "c"),
+		NextReference:    binding("next channel or user reference", "l"),
+		ToggleExpand:     binding("expand or collapse long message", "e"),
+		CopyRaw:          binding("copy raw JSON (with --debug)", "J"),
+		React:            binding("react with any emoji", "r"),
+		LocalPin:         binding("pin to the top, only for you", "P"),
+		MarkHandled:      binding("mark handled, only for you", "x"),
+	}
+}
+
+// inputBindings returns the bindings active while typing, by action name.
+func (k *keyMap) inputBindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":              &k.Quit,
+		"cancel":            &k.Cancel,
+		"send":              &k.Send,
+		"history-up":        &k.HistoryUp,
+		"history-down":      &k.HistoryDown,
+		"edit-last":         &k.EditLast,
+		"retry-send":        &k.RetrySend,
+		"ack":               &k.Ack,
+		"channel-back":      &k.ChannelBack,
+		"channel-forward":   &k.ChannelForward,
+		"bold":              &k.Bold,
+		"italic":            &k.Italic,
+		"next-pane":         &k.NextPane,
+		"code":              &k.Code,
+		"editor":            &k.Editor,
+		"open-file":         &k.OpenFile,
+		"select":            &k.Select,
+		"jump-bottom":       &k.JumpBottom,
+		"toggle-timestamps": &k.ToggleTimestamps,
+		"page-up":           &k.PageUp,
+		"page-down":         &k.PageDown,
+		"prev-message":      &k.PrevMessage,
+		"next-message":      &k.NextMessage,
+		"help":              &k.Help,
+		"error-log":         &k.ErrorLog,
+		"toggle-polling":    &k.TogglePolling,
+	}
+}
+
+// selectionBindings returns the bindings active while selecting messages,
+// by action name. Keys not bound here are ignored.
+func (k *keyMap) selectionBindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":               &k.Quit,
+		"unselect":           &k.Unselect,
+		"insert":             &k.Insert,
+		"delete":             &k.Delete,
+		"select-up":          &k.SelectUp,
+		"select-down":        &k.SelectDown,
+		"select-first":       &k.SelectFirst,
+		"select-last":        &k.SelectLast,
+		"save":               &k.Save,
+		"open-selected-file": &k.OpenSelectedFile,
+		"reaction-page":      &k.ReactionPage,
+		"jump-quoted":        &k.JumpQuoted,
+		"open-thread":        &k.OpenThread,
+		"show-profile":       &k.ShowProfile,
+		"copy-code":          &k.CopyCode,
+		"next-reference":     &k.NextReference,
+		"toggle-expand":      &k.ToggleExpand,
+		"copy-raw":           &k.CopyRaw,
+		"react":              &k.React,
+		"local-pin":          &k.LocalPin,
+		"mark-handled":       &k.MarkHandled,
+		"page-up":            &k.PageUp,
+		"page-down":          &k.PageDown,
+		"prev-message":       &k.PrevMessage,
+		"next-message":       &k.NextMessage,
+		"help":               &k.Help,
+		"error-log":          &k.ErrorLog,
+		"toggle-polling":     &k.TogglePolling,
+	}
+}
+
+// newKeyMap applies the remapped keys from the config on top of the
+// defaults, rejecting unknown actions and keys bound to several actions.
+func newKeyMap(overrides map[string][]string) (keyMap, error) {
+	k := defaultKeyMap()
+
+	all := k.inputBindings()
+	for name, b := range k.selectionBindings() {
+		all[name] = b
+	}
+
+	for name, keys := range overrides {
+		b, ok := all[name]
+		if !ok {
+			return keyMap{}, fmt.Errorf("unknown key binding action %q", name)
+		}
+		if len(keys) == 0 {
+			return keyMap{}, fmt.Errorf("no keys given for action %q", name)
+		}
+
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	}
+
+	for _, group := range []map[string]*key.Binding{k.inputBindings(), k.selectionBindings()} {
+		if err := checkConflicts(group); err != nil {
+			return keyMap{}, err
+		}
+	}
+
+	return k, nil
+}
+
+// Actions allowed to share keys, by name in order: cancel only acts on an
+// edit or a staged message, or in vim mode, and the key quits otherwise.
+// next-pane only acts in the split view, the key is italic otherwise.
+// unselect takes precedence over quit while selecting.
+var sharedKeys = map[[2]string]bool{
+	{"cancel", "quit"}:      true,
+	{"italic", "next-pane"}: true,
+	{"quit", "unselect"}:    true,
+}
+
+func checkConflicts(bindings map[string]*key.Binding) error {
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	used := make(map[string]string)
+	for _, name := range names {
+		for _, k := range bindings[name].Keys() {
+			if other, ok := used[k]; ok && !sharedKeys[[2]string{other, name}] {
+				return fmt.Errorf("key %q is bound to both %q and %q", k, other, name)
+			}
+			used[k] = name
+		}
+	}
+
+	return nil
+}
+
+// helpText lists the key bindings for the help overlay.
+func (k *keyMap) helpText() string {
+	var out strings.Builder
+	for _, group := range []struct {
+		title    string
+		bindings map[string]*key.Binding
+	}{
+		{"Input", k.inputBindings()},
+		{"Selecting messages", k.selectionBindings()},
+	} {
+		out.WriteString(usernameStyle.Render(group.title) + "\n")
+
+		names := make([]string, 0, len(group.bindings))
+		for name := range group.bindings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			help := group.bindings[name].Help()
+			out.WriteString(fmt.Sprintf("  %-20s %-24s %s\n", help.Key, help.Desc, timeStyle.Render(name)))
+		}
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
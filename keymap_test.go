@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestNewKeyMapConflicts(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		overrides map[string][]string
+		wantErr   bool
+	}{
+		{"defaults", nil, false},
+		{"cancel shares esc with quit", map[string][]string{"cancel": {"esc"}, "quit": {"esc", "ctrl+q"}}, false},
+		{"send on esc", map[string][]string{"send": {"esc"}}, true},
+		{"quit on a selection key", map[string][]string{"quit": {"ctrl+c", "j"}}, true},
+		{"unknown action", map[string][]string{"launch": {"ctrl+l"}}, true},
+	} {
+		_, err := newKeyMap(tc.overrides)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// KeywordStyle colors a keyword wherever it shows up in messages.
+type KeywordStyle struct {
+	Keyword string `json:"keyword"`
+
+	// Color is an ANSI color number or a hex code
+	Color string `json:"color"`
+	Bold  bool   `json:"bold,omitempty"`
+
+	// CaseSensitive only matches the keyword as written
+	CaseSensitive bool `json:"case_sensitive,omitempty"`
+}
+
+// keywordStyler colors the configured keywords, with a capture group per
+// rule in a single expression to tell which one matched.
+type keywordStyler struct {
+	re     *regexp.Regexp
+	styles []lipgloss.Style
+}
+
+func newKeywordStyler(rules []KeywordStyle) *keywordStyler {
+	var patterns []string
+	var styles []lipgloss.Style
+	for _, rule := range rules {
+		if rule.Keyword == "" {
+			continue
+		}
+
+		pattern := regexp.QuoteMeta(rule.Keyword)
+		if !rule.CaseSensitive {
+			pattern = "(?i:" + pattern + ")"
+		}
+		patterns = append(patterns, "("+pattern+")")
+		styles = append(styles, lipgloss.NewStyle().Foreground(lipgloss.Color(rule.Color)).Bold(rule.Bold))
+	}
+
+	if len(patterns) == 0 {
+		return nil
+	}
+	return &keywordStyler{regexp.MustCompile(strings.Join(patterns, "|")), styles}
+}
+
+// render colors the keywords in text, passing every piece through plain
+// first, e.g. to highlight the filter term.
+func (k *keywordStyler) render(text string, plain func(string) string) string {
+	if k == nil {
+		return plain(text)
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, match := range k.re.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(plain(text[last:match[0]]))
+		last = match[1]
+
+		// The first group that took part in the match is the rule
+		for i := range k.styles {
+			if match[2+2*i] >= 0 {
+				out.WriteString(k.styles[i].Render(plain(text[match[0]:match[1]])))
+				break
+			}
+		}
+	}
+	out.WriteString(plain(text[last:]))
+
+	return out.String()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type linkedMessageMsg struct {
+	link    string
+	message *Message
+	err     error
+}
+
+// parseSlackLink returns the channel and timestamp of the message a Slack
+// permalink such as https://team.slack.com/archives/C0123ABCD/p1700000000123456
+// points to.
+func parseSlackLink(url string) (channelID, ts string, ok bool) {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return "", "", false
+	}
+
+	m := permalinkRe.FindStringSubmatch(url)
+	if m == nil {
+		return "", "", false
+	}
+
+	return m[1], m[2] + "." + m[3], true
+}
+
+// pastedLink returns the message link the input holds, when that's all it
+// holds.
+func pastedLink(text string) (string, bool) {
+	link := strings.TrimSpace(text)
+	if strings.ContainsAny(link, " \t") {
+		return "", false
+	}
+	if _, _, ok := parseSlackLink(link); !ok {
+		return "", false
+	}
+	return link, true
+}
+
+// offerQuote asks to confirm quoting the message a link points to the
+// first time it's sent, reporting whether it's confirmed.
+func (m *model) offerQuote(link string) bool {
+	if m.quotePrompt == link {
+		m.quotePrompt = ""
+		return true
+	}
+
+	m.quotePrompt = link
+	m.setStatus("press Enter again to quote the linked message, or type anything to send the link as text")
+	return false
+}
+
+// declineQuote remembers that a link offered for quoting is meant to be
+// sent as text, once something else is typed.
+func (m *model) declineQuote() {
+	if m.quotePrompt != "" {
+		m.quoteDeclined = m.quotePrompt
+		m.quotePrompt = ""
+	}
+}
+
+func (m *model) fetchLinked(link string) tea.Cmd {
+	channelID, ts, _ := parseSlackLink(link)
+	m.setStatus("loading the linked message…")
+
+	client := m.client
+	return func() tea.Msg {
+		msg, err := client.Message(channelID, ts)
+		return linkedMessageMsg{link, msg, err}
+	}
+}
+
+// quoteLinked stages the linked message as a quote followed by the link,
+// to be sent as is or added to in the editor. When we can't read it, the
+// link is put back to be sent as text.
+func (m *model) quoteLinked(msg linkedMessageMsg) {
+	if msg.err != nil {
+		var slackErr *SlackError
+		if errors.As(msg.err, &slackErr) && (slackErr.Code == "channel_not_found" || slackErr.Code == "not_in_channel") {
+			m.setStatus("can't quote a message from a channel we're not in, Enter sends the link as text")
+		} else {
+			m.logError(fmt.Errorf("could not load the linked message: %w", msg.err))
+		}
+		m.quoteDeclined = msg.link
+		m.loadDraft(msg.link)
+		return
+	}
+
+	username, err := m.client.UsernameForMessage(*msg.message)
+	if err != nil {
+		username = "unknown"
+	}
+
+	var quote strings.Builder
+	quote.WriteString(fmt.Sprintf("> *%s* wrote:\n", username))
+	for _, line := range strings.Split(msg.message.Text, "\n") {
+		quote.WriteString("> " + line + "\n")
+	}
+	quote.WriteString(msg.link)
+
+	m.staged = quote.String()
+	m.input.Reset()
+	m.setStatus(fmt.Sprintf("linked message quoted, %s to add your reply", m.keys.Editor.Help().Key))
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Messages pinned to the top at most, so they leave room for the rest
+const maxLocalPins = 3
+
+// Rows the header, input and banner take besides the messages
+const chromeHeight = 4
+
+type localPinMsg struct {
+	channelID string
+	ts        string
+	message   *Message
+	err       error
+}
+
+// availableHeight is what's left of the window height for the messages,
+// below the messages pinned to the top.
+func (m *model) availableHeight(height int) int {
+	return height - chromeHeight - len(m.localPins)
+}
+
+// messagesHeight is the height of the messages viewport, leaving room for
+// the other panes of the split view.
+func (m *model) messagesHeight(height int) int {
+	available := m.availableHeight(height)
+	return available - len(m.otherPanes())*m.paneHeight(available)
+}
+
+// resizeMessages makes room for what's shown around the messages when it
+// changes, like pinning one.
+func (m *model) resizeMessages() {
+	if !m.ready {
+		return
+	}
+	m.viewport.Height = m.messagesHeight(m.windowHeight)
+	m.updateViewportContent()
+}
+
+// toggleLocalPin pins the selected message to the top of the view, or
+// unpins it. Unlike Slack pins, only we see them.
+func (m *model) toggleLocalPin() {
+	selected, ok := m.selectedMessage()
+	if !ok || selected.message.Ts == "" {
+		return
+	}
+	ts := selected.message.Ts
+
+	pins := m.localPins
+	for i, pinned := range pins {
+		if pinned == ts {
+			m.localPins = append(pins[:i:i], pins[i+1:]...)
+			m.setStatus("unpinned from the top")
+			m.saveLocalPins()
+			return
+		}
+	}
+
+	if len(pins) >= maxLocalPins {
+		m.setStatus(fmt.Sprintf("%d messages pinned to the top already, unpin one first", maxLocalPins))
+		return
+	}
+
+	message := selected.message
+	m.pinnedMessages[ts] = &message
+	m.localPins = append(pins[:len(pins):len(pins)], ts)
+	m.setStatus("pinned to the top, only for you")
+	m.saveLocalPins()
+}
+
+func (m *model) saveLocalPins() {
+	if len(m.localPins) == 0 {
+		delete(m.config.LocalPins, m.channelID)
+	} else {
+		if m.config.LocalPins == nil {
+			m.config.LocalPins = make(map[string][]string)
+		}
+		m.config.LocalPins[m.channelID] = m.localPins
+	}
+	if err := m.config.save(); err != nil {
+		m.logError(err)
+	}
+
+	m.resizeMessages()
+}
+
+// fetchLocalPins loads the pinned messages of the channel, which may be
+// older than the ones loaded.
+func (m *model) fetchLocalPins() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, ts := range m.localPins {
+		if _, ok := m.pinnedMessages[ts]; ok {
+			continue
+		}
+
+		// Nil until it arrives, so it's only fetched once
+		m.pinnedMessages[ts] = nil
+
+		client, channelID := m.client, m.channelID
+		cmds = append(cmds, func() tea.Msg {
+			message, err := client.Message(channelID, ts)
+			return localPinMsg{channelID, ts, message, err}
+		})
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (m *model) addLocalPin(msg localPinMsg) {
+	if msg.channelID != m.channelID {
+		return
+	}
+	if msg.err != nil {
+		delete(m.pinnedMessages, msg.ts)
+		m.logError(fmt.Errorf("could not load a message pinned to the top: %w", msg.err))
+		return
+	}
+
+	m.pinnedMessages[msg.ts] = msg.message
+}
+
+// renderLocalPins renders a line for each pinned message, preferring the
+// loaded copy so edits show.
+func (m model) renderLocalPins() string {
+	if len(m.localPins) == 0 {
+		return ""
+	}
+
+	loaded := make(map[string]Message)
+	for _, msg := range m.messages {
+		loaded[msg.message.Ts] = msg.message
+	}
+
+	lines := make([]string, 0, len(m.localPins))
+	for _, ts := range m.localPins {
+		message, ok := loaded[ts]
+		if !ok {
+			pinned, fetched := m.pinnedMessages[ts]
+			switch {
+			case !fetched:
+				lines = append(lines, statusStyle.Render("📌 message not available"))
+				continue
+			case pinned == nil:
+				lines = append(lines, statusStyle.Render("📌 loading…"))
+				continue
+			}
+			message = *pinned
+		}
+
+		username, err := m.client.UsernameForMessage(message)
+		if err != nil {
+			username = "unknown"
+		}
+		text := strings.Join(strings.Fields(m.redact(message.Text)), " ")
+		line := "📌 " + usernameStyle.Render(username) + ": " + text
+		lines = append(lines, truncate(line, m.viewport.Width))
+	}
+
+	return strings.Join(lines, "\n")
+}
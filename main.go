@@ -1,17 +1,20 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,6 +26,8 @@ var (
 	timeStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	messageStyle  = lipgloss.NewStyle()
 	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	statusStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true)
+	unseenStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62"))
 
 	channelStyle = lipgloss.NewStyle().
 			Background(lipgloss.Color("62")).
@@ -37,35 +42,116 @@ var (
 
 // Message types
 type fetchMessagesMsg struct {
-	messages []Message
-	err      error
+	channelID      string
+	messages       []Message
+	err            error
+	throttledUntil time.Time
+	limited        bool // older history isn't available
+	hasOlder       bool // there are older messages than the latest fetched
 }
 
 type sendMessageMsg struct {
-	response *SendMessageResponse
-	err      error
+	channelID string
+	pendingID string // placeholder shown while sending
+	response  *SendMessageResponse
+	err       error
 }
 
+type permalinkMsg struct {
+	permalink string
+	err       error
+}
+
+// confirmSendMsg triggers another fetch while a sent message hasn't shown up
+// in the channel history yet
+type confirmSendMsg struct {
+	channelID string
+	ts        string
+	attempt   int
+}
+
+type updateMessageMsg struct {
+	channelID string
+	ts        string
+	text      string
+	err       error
+}
+
+const (
+	pollInterval = 2 * time.Second
+
+	// A tick arriving this much later than scheduled means the process was
+	// suspended (e.g. laptop sleep) and the connection may be stale.
+	resumeGapThreshold = 30 * time.Second
+
+	statusDuration = 5 * time.Second
+
+	// Long enough to copy the link of a message we just sent
+	permalinkStatusDuration = 15 * time.Second
+
+	// After sending, history is polled at increasing intervals starting at
+	// confirmDelay until the sent message shows up
+	confirmDelay       = 250 * time.Millisecond
+	maxConfirmAttempts = 5
+
+	// Slack truncates messages longer than this
+	maxMessageLength = 40000
+)
+
 type tickMsg time.Time
 
+// resumeMsg reports the result of re-validating the connection after a
+// suspend/resume was detected.
+type resumeMsg struct {
+	channel *Channel
+	err     error
+}
+
+// idleMsg fires when the idle timeout may have been reached
+type idleMsg struct{}
+
 // This is a new message type to explicitly trigger a redraw
 type redrawViewportMsg struct{}
 
+// overlay is a full-screen text view shown in place of the messages, such
+// as the content of a file. Esc closes it.
+type overlay struct {
+	title   string
+	content string
+
+	// scroll position of the messages, restored on close
+	prevOffset int
+}
+
+// Delivery state of messages we sent
+type sendState int
+
+const (
+	stateConfirmed sendState = iota // seen in the channel history
+	stateSending                    // waiting for chat.postMessage
+	stateSent                       // accepted by Slack, not polled yet
+)
+
 type formattedMessage struct {
-	text      string
+	message   Message
+	username  string
 	timestamp time.Time
 	id        string // message ID (ts)
+	state     sendState
 }
 
 type model struct {
 	client       *SlackClient
+	config       *Config
+	keys         keyMap
+	selfID       string
 	channelID    string
 	channelName  string
+	channel      *Channel // nil if the channel info isn't available
 	messages     []formattedMessage
 	messageIDs   map[string]bool
 	input        textinput.Model
 	viewport     viewport.Model
-	err          error
 	ready        bool
 	lastFetched  string
 	history      []string
@@ -74,17 +160,233 @@ type model struct {
 	browsingHist bool
 	refreshCount int
 	needsRedraw  bool // Flag to indicate the viewport needs redrawing
+
+	// Last key typed into the input, to hold redraws while composing
+	lastKeystroke time.Time
+
+	showTimestamps bool
+
+	tickDue     time.Time
+	status      string
+	statusUntil time.Time
+
+	// Polling backs off until then when Slack rate limits us
+	throttledUntil time.Time
+
+	// Polling is skipped while paused, to read without the view moving
+	paused bool
+
+	// loaded is set once the first fetch for the channel is in, so the
+	// existing backlog doesn't trigger mention alerts.
+	loaded bool
+
+	// Terminal focus, when the terminal reports it
+	focused    bool
+	focusKnown bool
+
+	overlay *overlay
+
+	// Message selection, when the messages have the focus (normal mode in
+	// vim mode)
+	selecting     bool
+	selected      int
+	pendingDelete bool
+	messageRows   []int // first viewport row of each message, -1 if hidden
+	messageLines  []int // number of rendered lines of each message
+
+	// Long messages shown in full, by timestamp
+	expanded map[string]bool
+
+	// Workspace name shown in the header
+	teamName string
+
+	// Enables debugging aids, like copying the raw messages
+	debug bool
+
+	// The terminal, shared with the renderer so the bell doesn't land
+	// in the middle of a frame
+	output io.Writer
+
+	// Keywords colored in messages, nil if none are configured
+	keywords *keywordStyler
+
+	// Patterns masked in the messages shown, with --redact
+	redactions []*regexp.Regexp
+
+	// Bookmarks of the channel listed by /bookmarks
+	bookmarks []Bookmark
+
+	// Open while picking an emoji to react with
+	emojiPicker *emojiPicker
+
+	// Results of the last /search
+	search *search
+
+	// Dropped file waiting for Enter again to be uploaded, and the last one
+	// that's to be sent as text instead
+	uploadPrompt   string
+	uploadDeclined string
+
+	// Message link waiting for Enter again to be quoted, and the last one
+	// that's to be sent as text instead
+	quotePrompt   string
+	quoteDeclined string
+
+	// Set by /archive until it's run again to confirm
+	pendingArchive bool
+
+	// Arguments of a /crosspost to many channels, until it's run again
+	pendingCrosspost string
+
+	// Quit to the channel picker, e.g. after archiving the channel
+	repick bool
+
+	// Why polling the channel fails, when it was deleted or we were removed
+	goneReason string
+
+	// Large message waiting for Enter again to be sent
+	pendingLarge string
+
+	// Text of the last message that failed to send, until retried
+	lastFailedSend string
+	lastFailedAt   time.Time
+
+	// Only messages matching this are shown, highlighted with filterRe
+	filter   string
+	filterRe *regexp.Regexp
+
+	// Only show messages with this reaction, or without it if it starts
+	// with '!'
+	reactFilter string
+
+	// Focused user or channel reference in the selected message, -1 if none
+	refIndex int
+
+	// Reactions to the selected message, paginated to fit the header
+	reactionPages []string
+	reactionPage  int
+
+	// Messages saved for later, keyed by savedKey
+	saved map[string]bool
+
+	// Quit after this long without a keystroke; zero means never
+	idleTimeout  time.Duration
+	lastActivity time.Time
+
+	// Messages that arrived while scrolled up
+	unseenCount int
+
+	// Our last sent message in this channel, and the message being edited
+	lastSentTs   string
+	lastSentText string
+	editingTs    string
+
+	// Sequence for the placeholder IDs of messages being sent
+	pendingSeq int
+
+	// Multi-line message waiting to be sent, e.g. written in $EDITOR
+	staged string
+
+	// Message highlighted after jumping to it
+	flashTs string
+
+	// Show author badges before each group of messages
+	avatars bool
+
+	// How our own messages stand out
+	selfStyle lipgloss.Style
+	alignSelf bool
+
+	// Where sent messages are kept, and the prefix of this channel's lines
+	historyStore  historyStore
+	historyPrefix string
+
+	// False when the history can't be written, it's kept in memory then
+	historyEnabled bool
+
+	// Latest reply of threads by its timestamp, empty while loading
+	threadPreviews map[string]string
+
+	// Translations of messages by timestamp, empty while loading, when
+	// translation is configured
+	translator      Translator
+	translations    map[string]string
+	translateFailed bool
+
+	// Timestamps of the messages pinned to the top of the current channel,
+	// and the pinned messages by timestamp, nil while loading
+	localPins      []string
+	pinnedMessages map[string]*Message
+
+	windowHeight int
+
+	// Styles of the current channel, see channel_themes
+	theme theme
+
+	// Timestamps of the messages we marked handled in the current channel,
+	// and whether only the others are shown
+	handled       map[string]bool
+	unhandledOnly bool
+
+	// Message and index of the code block copied last
+	copiedTs    string
+	copiedBlock int
+
+	// Our latest mentions across channels, nil until loaded, and the
+	// message /activity is jumping to
+	mentions    []Mention
+	pendingJump string
+
+	// Slack's Do Not Disturb status, nil until known, and whether its
+	// refresh is failing
+	dnd          *DndInfo
+	dndReadFails bool
+
+	// Errors of this session, see logError
+	errorLog []errEntry
+
+	// The workspace doesn't let us see the channel's older messages
+	historyLimited bool
+
+	// The channel has older messages than those loaded
+	olderAvailable bool
+
+	// Error of the last poll, cleared by the next successful one
+	lastFetchErr error
+
+	// Polls in a row that couldn't reach Slack at all
+	connFailures int
+
+	// Channels polled in the background
+	watched []*watchedChannel
+
+	// Shows the watched channels in panes, with --split
+	split bool
+
+	// Visited channel IDs, for going back and forward
+	channelHistory      []string
+	channelHistoryIndex int
 }
 
-func initialModel(client *SlackClient, channelID string) (model, error) {
+func initialModel(client *SlackClient, config *Config, channelID string) (model, error) {
 	// Get channel info to display the name in the UI
 	var channelName string
 	channel, err := client.ChannelInfo(channelID)
 	if err != nil {
 		// If we can't get the channel info, just use the ID as the name
 		channelName = channelID
+		channel = nil
 	} else {
-		channelName = channel.Name
+		channelName = client.ChannelDisplayName(channel)
+	}
+
+	// Without our own user ID we can't tell mentions from our own messages,
+	// so alerts just get noisier; not worth failing over.
+	selfID, _ := client.CurrentUserID()
+
+	keys, err := newKeyMap(config.Keys)
+	if err != nil {
+		return model{}, err
 	}
 
 	// Use textinput instead of textarea for single line
@@ -92,44 +394,40 @@ func initialModel(client *SlackClient, channelID string) (model, error) {
 	ti.Placeholder = "Send a message..."
 	ti.Focus()
 	ti.Width = 30
-	ti.CharLimit = 4000
-	ti.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
-	ti.Prompt = "➤ "
+	ti.CharLimit = maxMessageLength
+	config.applyPrompt(&ti)
 
-	vp := viewport.New(30, 10)
+	vp := newViewport(30, 10, keys)
 	vp.SetContent("")
 
-	// Get user home directory for history file
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
+	if err := checkHistoryFormat(config); err != nil {
 		return model{}, err
 	}
 
-	// Ensure directory exists
-	historyDir := filepath.Join(homeDir, ".slack-chat-history")
-	if err := os.MkdirAll(historyDir, 0755); err != nil {
-		return model{}, err
+	// Read-only home directories shouldn't stop us from chatting, the
+	// history is just kept in memory then
+	historyEnabled := true
+	historyStore, historyErr := newHistoryStore(config)
+	if historyErr != nil {
+		historyEnabled = false
 	}
 
-	historyFile := filepath.Join(historyDir, fmt.Sprintf("%s-%s.history", client.team, channelID))
-
-	// Load history from file
+	// Without a store there's no directory, so no file to use
+	var historyFile, historyPrefix string
 	history := []string{}
-	if _, err := os.Stat(historyFile); err == nil {
-		file, err := os.Open(historyFile)
-		if err == nil {
-			defer file.Close()
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				history = append(history, scanner.Text())
-			}
-		}
+	if historyEnabled {
+		historyFile, historyPrefix = historyStore.path(client.team, channelID)
+		history = loadHistory(historyFile, historyPrefix)
 	}
 
 	m := model{
 		client:       client,
+		config:       config,
+		keys:         keys,
+		selfID:       selfID,
 		channelID:    channelID,
 		channelName:  channelName,
+		channel:      channel,
 		messages:     []formattedMessage{},
 		messageIDs:   make(map[string]bool),
 		input:        ti,
@@ -141,22 +439,144 @@ func initialModel(client *SlackClient, channelID string) (model, error) {
 		browsingHist: false,
 		refreshCount: 0,
 		needsRedraw:  false,
+
+		showTimestamps: config.ShowTimestamps,
+		focused:        true,
+		saved:          make(map[string]bool),
+		lastActivity:   time.Now(),
+		channelHistory: []string{channelID},
+		threadPreviews: make(map[string]string),
+		translations:   make(map[string]string),
+		localPins:      config.LocalPins[channelID],
+		pinnedMessages: make(map[string]*Message),
+		historyPrefix:  historyPrefix,
+		historyStore:   historyStore,
+		historyEnabled: historyEnabled,
+		selfStyle:      usernameStyle.Foreground(lipgloss.Color(config.SelfColor)),
+		refIndex:       -1,
+		teamName:       client.team,
+		keywords:       newKeywordStyler(config.KeywordStyles),
+		theme:          config.channelTheme(channelID, channelName),
+	}
+
+	if historyErr != nil {
+		m.logError(fmt.Errorf("sent messages won't be saved across sessions: %w", historyErr))
+	}
+	if config.Translate != nil {
+		m.translator = newTranslator(config.Translate)
 	}
+	m.loadChannelHandled()
 
 	return m, nil
 }
 
+// newViewport creates the messages viewport. Only the page keys scroll it,
+// everything else is typed into the input.
+func newViewport(width, height int, keys keyMap) viewport.Model {
+	vp := viewport.New(width, height)
+	vp.KeyMap = viewport.KeyMap{
+		PageDown: keys.PageDown,
+		PageUp:   keys.PageUp,
+	}
+	return vp
+}
+
+// switchChannel points the model at a different channel, dropping the
+// messages loaded for the previous one.
+func (m *model) switchChannel(channelID, channelName string, channel *Channel) tea.Cmd {
+	m.channelID = channelID
+	m.channelName = channelName
+	m.channel = channel
+	m.messages = []formattedMessage{}
+	m.messageIDs = make(map[string]bool)
+	m.lastFetched = ""
+	m.loaded = false
+	m.unseenCount = 0
+	m.historyLimited = false
+	m.olderAvailable = false
+	m.bookmarks = nil
+	m.expanded = nil
+	m.lastSentTs = ""
+	m.lastSentText = ""
+	m.editingTs = ""
+	m.selecting = false
+	m.goneReason = ""
+	m.pendingLarge = ""
+	m.localPins = m.config.LocalPins[channelID]
+	m.pinnedMessages = make(map[string]*Message)
+	m.theme = m.config.channelTheme(channelID, channelName)
+	m.loadChannelHandled()
+	m.resizeMessages()
+	m.input.Focus()
+
+	m.historyFile, m.historyPrefix, m.history = "", "", []string{}
+	if m.historyEnabled {
+		m.historyFile, m.historyPrefix = m.historyStore.path(m.client.team, channelID)
+		m.history = loadHistory(m.historyFile, m.historyPrefix)
+	}
+	m.historyIndex = len(m.history)
+	m.browsingHist = false
+
+	m.updateViewportContent()
+	return tea.Batch(m.prefetched(channelID), fetchMessages(m.client, m.channelID, "", m.config.FetchLimit), m.fetchLocalPins())
+}
+
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		tea.EnterAltScreen,
-		fetchMessages(m.client, m.channelID, m.lastFetched),
+		fetchMessages(m.client, m.channelID, m.lastFetched, m.config.FetchLimit),
 		textinput.Blink,
-		tick(),
+		tick(pollInterval),
+		listSaved(m.client, false),
+		checkIdle(m.idleTimeout),
+		m.startWatching(),
+		threadRefresh(),
+		fetchDnd(m.client),
+		fetchTeamName(m.client),
+		m.fetchLocalPins(),
+		checkScopes(m.client, m.channel),
 	)
 }
 
-func tick() tea.Cmd {
-	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
+type teamNameMsg string
+
+// fetchTeamName looks up the workspace's name for the header. Until then,
+// or if it fails, the team given on the command line is shown.
+func fetchTeamName(client *SlackClient) tea.Cmd {
+	return func() tea.Msg {
+		team, err := client.TeamInfo()
+		if err != nil || team.Name == "" {
+			return nil
+		}
+		return teamNameMsg(team.Name)
+	}
+}
+
+// checkIdle schedules the next idle timeout check, if there's a timeout.
+func checkIdle(after time.Duration) tea.Cmd {
+	if after <= 0 {
+		return nil
+	}
+
+	return tea.Tick(after, func(time.Time) tea.Msg {
+		return idleMsg{}
+	})
+}
+
+// quitIdle saves any unsent draft to the history before quitting, so it can
+// be recalled next time.
+func (m *model) quitIdle() tea.Cmd {
+	if draft := m.input.Value(); strings.TrimSpace(draft) != "" {
+		if err := m.appendToHistory(draft); err != nil {
+			m.logError(err)
+		}
+	}
+
+	return tea.Quit
+}
+
+func tick(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
@@ -168,13 +588,238 @@ func redrawViewport() tea.Cmd {
 	}
 }
 
-func sendMessage(client *SlackClient, channelID, text string) tea.Cmd {
+// Polls in a row that can't reach Slack before the connections are
+// considered stale
+const maxConnFailures = 3
+
+// reconnectIfStale starts over with fresh connections after a few polls in
+// a row fail to reach Slack, as happens after switching networks. Errors
+// returned by Slack itself don't count, the connection works then.
+func (m *model) reconnectIfStale(err error) {
+	var slackErr *SlackError
+	if errors.As(err, &slackErr) {
+		m.connFailures = 0
+		return
+	}
+
+	m.connFailures++
+	if m.connFailures < maxConnFailures {
+		return
+	}
+
+	m.connFailures = 0
+	m.client.Reset()
+	m.setStatus("network changed, reconnecting")
+}
+
+// revalidateConnection checks the connection still works after a resume,
+// refreshing the channel info along the way.
+func revalidateConnection(client *SlackClient, channelID string) tea.Cmd {
+	return func() tea.Msg {
+		channel, err := client.ChannelInfo(channelID)
+		return resumeMsg{channel, err}
+	}
+}
+
+func sendMessage(client *SlackClient, channelID, pendingID, text string, as Sender) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := client.SendMessageAs(channelID, text, as)
+		return sendMessageMsg{channelID, pendingID, resp, err}
+	}
+}
+
+// send posts a message, showing it right away. It's reconciled once Slack
+// accepts it.
+func (m *model) send(text string) tea.Cmd {
+	pendingID := m.addPending(text)
+	var as Sender
+	if m.config.PostAs != nil {
+		as = *m.config.PostAs
+	}
+	return sendMessage(m.client, m.channelID, pendingID, text, as)
+}
+
+// fetchPermalink looks up a message's permalink in the background, so
+// sending isn't held up by it.
+func fetchPermalink(client *SlackClient, channelID, ts string) tea.Cmd {
+	return func() tea.Msg {
+		permalink, err := client.Permalink(channelID, ts)
+		return permalinkMsg{permalink, err}
+	}
+}
+
+// parseTs converts a Slack message timestamp to a time.
+// Timestamps are seconds and microseconds, e.g. "1700000000.123456", and
+// messages in the same second are only told apart by the fraction.
+func parseTs(ts string) time.Time {
+	secs, frac, _ := strings.Cut(ts, ".")
+	sec, _ := strconv.ParseInt(secs, 10, 64)
+
+	// Pad or trim to microseconds, so ".5" means half a second
+	frac = (frac + "000000")[:6]
+	usec, _ := strconv.ParseInt(frac, 10, 64)
+
+	return time.Unix(sec, usec*int64(time.Microsecond))
+}
+
+// sortMessages orders the messages by time, falling back to the raw
+// timestamp for messages in the same microsecond.
+func (m *model) sortMessages() {
+	sort.SliceStable(m.messages, func(i, j int) bool {
+		a, b := m.messages[i], m.messages[j]
+		if !a.timestamp.Equal(b.timestamp) {
+			return a.timestamp.Before(b.timestamp)
+		}
+		return a.message.Ts < b.message.Ts
+	})
+}
+
+// addMessage appends a message from the channel history, unless it was
+// seen before, e.g. when a full refresh after a reconnect returns messages
+// we have. Reports whether it was added; the messages need sorting
+// afterwards.
+func (m *model) addMessage(message Message) bool {
+	if m.messageIDs[message.Ts] {
+		return false
+	}
+
+	username, err := m.client.UsernameForMessage(message)
+	if err != nil {
+		username = "unknown"
+	}
+
+	m.messages = append(m.messages, formattedMessage{
+		message:   message,
+		username:  username,
+		timestamp: parseTs(message.Ts),
+		id:        message.Ts,
+	})
+	m.messageIDs[message.Ts] = true
+	return true
+}
+
+// addPending shows a message we're sending straight away, before Slack has
+// accepted it, returning its placeholder ID.
+func (m *model) addPending(text string) string {
+	m.pendingSeq++
+	pendingID := fmt.Sprintf("pending-%d", m.pendingSeq)
+
+	username, err := m.client.UsernameForMessage(Message{User: m.selfID})
+	if err != nil {
+		username = "me"
+	}
+
+	m.messages = append(m.messages, formattedMessage{
+		message:   Message{User: m.selfID, Text: text},
+		username:  username,
+		timestamp: time.Now(),
+		id:        pendingID,
+		state:     stateSending,
+	})
+	m.updateViewportContent()
+
+	return pendingID
+}
+
+// resolvePending swaps a placeholder for the message Slack accepted. Its
+// ts is recorded so polling doesn't add it a second time. When polling was
+// faster than the response, the placeholder is dropped instead.
+func (m *model) resolvePending(pendingID string, resp *SendMessageResponse) {
+	if m.messageIDs[resp.TS] {
+		m.dropPending(pendingID)
+		return
+	}
+
+	for i := range m.messages {
+		if m.messages[i].id != pendingID {
+			continue
+		}
+
+		message := resp.Message
+		if message.Ts == "" {
+			message.Ts = resp.TS
+		}
+
+		m.messages[i].message = message
+		m.messages[i].id = resp.TS
+		m.messages[i].timestamp = parseTs(resp.TS)
+		m.messages[i].state = stateSent
+		m.messageIDs[resp.TS] = true
+	}
+
+	m.sortMessages()
+	m.updateViewportContent()
+}
+
+// confirmSend fetches the latest messages and schedules another attempt,
+// backing off each time, in case the sent message isn't there yet.
+func (m *model) confirmSend(ts string, attempt int) tea.Cmd {
+	channelID := m.channelID
+	return tea.Batch(
+		fetchMessages(m.client, channelID, "", m.config.FetchLimit),
+		tea.Tick(confirmDelay<<attempt, func(time.Time) tea.Msg {
+			return confirmSendMsg{channelID, ts, attempt + 1}
+		}),
+	)
+}
+
+func (m *model) awaitingConfirmation(ts string) bool {
+	for _, msg := range m.messages {
+		if msg.id == ts {
+			return msg.state == stateSent
+		}
+	}
+	return false
+}
+
+// dropPending removes the placeholder of a message that failed to send,
+// returning its text.
+func (m *model) dropPending(pendingID string) string {
+	text := ""
+	for i := range m.messages {
+		if m.messages[i].id == pendingID {
+			text = m.messages[i].message.Text
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			break
+		}
+	}
+	m.updateViewportContent()
+	return text
+}
+
+func updateMessage(client *SlackClient, channelID, ts, text string) tea.Cmd {
 	return func() tea.Msg {
-		resp, err := client.SendMessage(channelID, text)
-		return sendMessageMsg{resp, err}
+		_, err := client.UpdateMessage(channelID, ts, text)
+		return updateMessageMsg{channelID, ts, text, err}
+	}
+}
+
+// editLastSent loads our last sent message into the input so Enter updates
+// it rather than sending a new one.
+func (m *model) editLastSent() {
+	if m.lastSentTs == "" {
+		m.setStatus("nothing sent yet in this channel")
+		return
 	}
+
+	text := m.lastSentText
+	for _, msg := range m.messages {
+		if msg.id == m.lastSentTs {
+			text = msg.message.Text
+			break
+		}
+	}
+
+	m.editingTs = m.lastSentTs
+	m.input.SetValue(text)
+	m.input.CursorEnd()
+	m.browsingHist = false
 }
 
+func (m *model) cancelEdit() {
+	m.editingTs = ""
+	m.input.Reset()
+}
 func (m *model) appendToHistory(message string) error {
 	// Don't add empty messages or duplicates of the last message
 	if strings.TrimSpace(message) == "" {
@@ -187,17 +832,56 @@ func (m *model) appendToHistory(message string) error {
 	m.history = append(m.history, message)
 	m.historyIndex = len(m.history)
 
+	if !m.historyEnabled {
+		return nil
+	}
+
 	// Write to file
 	file, err := os.OpenFile(m.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return err
+		// Only warn once, the history is kept in memory from now on
+		m.historyEnabled = false
+		return fmt.Errorf("sent messages won't be saved across sessions: %w", err)
 	}
 	defer file.Close()
 
-	_, err = file.WriteString(message + "\n")
+	_, err = file.WriteString(m.historyPrefix + message + "\n")
 	return err
 }
 
+// Slack mrkdwn markers for the formatting shortcuts
+var formatMarkers = map[string]string{
+	"bold":   "*",
+	"italic": "_",
+	"code":   "`",
+}
+
+// applyFormat wraps value in the mrkdwn markers for format, or unwraps it
+// when it's already wrapped so the shortcut acts as a toggle.
+func applyFormat(value string, format string) string {
+	marker, ok := formatMarkers[format]
+	if !ok {
+		return value
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) >= 2*len(marker) && strings.HasPrefix(trimmed, marker) && strings.HasSuffix(trimmed, marker) {
+		return strings.TrimSuffix(strings.TrimPrefix(trimmed, marker), marker)
+	}
+
+	return marker + trimmed + marker
+}
+
+func (m *model) formatInput(format string) {
+	value := m.input.Value()
+	m.input.SetValue(applyFormat(value, format))
+
+	// With nothing typed yet, leave the cursor between the markers
+	if strings.TrimSpace(value) == "" {
+		m.input.SetCursor(len(formatMarkers[format]))
+	}
+}
+
 func (m *model) navigateHistory(direction int) {
 	newIndex := m.historyIndex + direction
 
@@ -232,170 +916,933 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyEsc, tea.KeyCtrlC:
+		m.lastActivity = time.Now()
+
+		if m.emojiPicker != nil {
+			return m, m.handleEmojiKey(msg)
+		}
+
+		if cmd, ok := m.handleSearchKey(msg); ok {
+			return m, cmd
+		}
+
+		if m.overlay != nil {
+			switch {
+			case key.Matches(msg, m.keys.Unselect):
+				m.closeOverlay()
+				return m, nil
+			case key.Matches(msg, m.keys.SelectUp):
+				m.viewport.ScrollUp(1)
+				return m, nil
+			case key.Matches(msg, m.keys.SelectDown):
+				m.viewport.ScrollDown(1)
+				return m, nil
+			}
+		}
+
+		if m.selecting {
+			cmd := m.handleSelectionKey(msg)
+			return m, cmd
+		}
+
+		if !key.Matches(msg, m.keys.Send) {
+			m.declineUpload()
+			m.declineQuote()
+		}
+
+		if isMultilinePaste(msg) {
+			m.pasteMultiline(msg)
+			return m, nil
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Cancel) && m.editingTs != "":
+			m.cancelEdit()
+			return m, nil
+		case key.Matches(msg, m.keys.Cancel) && m.staged != "":
+			m.discardStaged()
+			return m, nil
+		case key.Matches(msg, m.keys.Cancel) && m.config.VimMode:
+			m.startSelection()
+			return m, nil
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
-		case tea.KeyEnter:
-			if strings.TrimSpace(m.input.Value()) != "" {
+		case key.Matches(msg, m.keys.Send):
+			// Commands typed while a message is staged can act on it
+			if m.staged != "" && !isCommand(m.input.Value()) {
+				if !m.confirmLarge(m.staged) {
+					return m, nil
+				}
+				cmds = append(cmds, m.send(m.staged))
+				m.discardStaged()
+				m.flushRedraw()
+				m.jumpToBottom()
+			} else if path, ok := droppedFile(m.input.Value()); ok && m.editingTs == "" && path != m.uploadDeclined {
+				// A dragged in file, which is uploaded once confirmed
+				if m.offerUpload(path) {
+					m.input.Reset()
+					return m, m.uploadFile(path)
+				}
+				return m, nil
+			} else if link, ok := pastedLink(m.input.Value()); ok && m.editingTs == "" && link != m.quoteDeclined {
+				// A pasted message link, whose message is quoted once confirmed
+				if m.offerQuote(link) {
+					m.input.Reset()
+					return m, m.fetchLinked(link)
+				}
+				return m, nil
+			} else if strings.TrimSpace(m.input.Value()) != "" {
+				m.uploadDeclined = ""
+				m.quoteDeclined = ""
 				text := m.input.Value()
+				if m.editingTs == "" && !isCommand(text) && !m.confirmLarge(text) {
+					return m, nil
+				}
 				err := m.appendToHistory(text)
 				if err != nil {
-					m.err = err
+					m.logError(err)
 				}
 
-				// Immediately send the message and then fetch updated messages
-				cmds = append(cmds, tea.Sequence(
-					sendMessage(m.client, m.channelID, text),
-					// Increased delay to allow server to process
-					tea.Tick(500*time.Millisecond, func(time.Time) tea.Msg {
-						return fetchMessagesMsg{nil, nil}
-					}),
-				))
+				if m.editingTs != "" {
+					cmds = append(cmds, updateMessage(m.client, m.channelID, m.editingTs, text))
+					m.editingTs = ""
+				} else if isCommand(text) {
+					cmds = append(cmds, m.runCommand(text))
+				} else {
+					cmds = append(cmds, m.send(unescapeCommand(text)))
+				}
 
 				m.input.Reset()
 				m.browsingHist = false
+				m.flushRedraw()
+				m.jumpToBottom()
 			}
-		case tea.KeyUp:
+		case key.Matches(msg, m.keys.EditLast):
+			m.editLastSent()
+			return m, nil
+		case key.Matches(msg, m.keys.RetrySend):
+			return m, m.retrySend()
+		case key.Matches(msg, m.keys.Ack):
+			return m, m.ack()
+		case key.Matches(msg, m.keys.ChannelBack):
+			return m, m.navigateChannels(-1)
+		case key.Matches(msg, m.keys.ChannelForward):
+			return m, m.navigateChannels(1)
+		case key.Matches(msg, m.keys.HistoryUp):
 			m.navigateHistory(-1)
 			return m, nil
-		case tea.KeyDown:
+		case key.Matches(msg, m.keys.HistoryDown):
 			m.navigateHistory(1)
 			return m, nil
+		case key.Matches(msg, m.keys.Bold):
+			m.formatInput("bold")
+			return m, nil
+		case m.split && key.Matches(msg, m.keys.NextPane):
+			return m, m.focusNextPane()
+		case key.Matches(msg, m.keys.Italic):
+			m.formatInput("italic")
+			return m, nil
+		case key.Matches(msg, m.keys.Code):
+			m.formatInput("code")
+			return m, nil
+		case key.Matches(msg, m.keys.Editor):
+			return m, m.composeInEditor(m.draft(), false)
+		case key.Matches(msg, m.keys.OpenFile):
+			file, ok := m.latestTextFile()
+			if !ok {
+				m.setStatus("no text files shared in this channel")
+				return m, nil
+			}
+			m.setStatus(fmt.Sprintf("loading %s…", file.Name))
+			return m, fetchFileContent(m.client, file)
+		case key.Matches(msg, m.keys.Select):
+			m.startSelection()
+			return m, nil
+		case key.Matches(msg, m.keys.Help):
+			m.openOverlay("Key bindings", m.keys.helpText())
+			return m, nil
+		case key.Matches(msg, m.keys.ErrorLog):
+			m.openOverlay("Errors", m.errorLogText())
+			return m, nil
+		case key.Matches(msg, m.keys.TogglePolling):
+			return m, m.togglePolling()
+		case key.Matches(msg, m.keys.JumpBottom):
+			if !m.viewport.AtBottom() {
+				m.jumpToBottom()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.PrevMessage):
+			m.scrollToMessage(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.NextMessage):
+			m.scrollToMessage(1)
+			return m, nil
+		case key.Matches(msg, m.keys.ToggleTimestamps):
+			m.showTimestamps = !m.showTimestamps
+			m.config.ShowTimestamps = m.showTimestamps
+			if err := m.config.save(); err != nil {
+				m.logError(err)
+			}
+			m.updateViewportContent()
+			return m, nil
 		}
 
 	case tea.WindowSizeMsg:
 		height = msg.Height
 		width = msg.Width
+		m.windowHeight = height
 
 		if !m.ready {
-			m.viewport = viewport.New(width, height-4)
+			m.viewport = newViewport(m.messagesWidth(width), m.messagesHeight(height), m.keys)
 			m.input.Width = width - 4 // Account for prompt and some padding
 			m.ready = true
 		} else {
-			m.viewport.Width = width
-			m.viewport.Height = height - 4
+			m.viewport.Width = m.messagesWidth(width)
+			m.viewport.Height = m.messagesHeight(height)
 			m.input.Width = width - 4 // Account for prompt and some padding
 		}
 		m.updateViewportContent()
 
-	case redrawViewportMsg:
-		// This message just forces a redraw of the viewport
-		m.updateViewportContent()
+	case idleMsg:
+		if m.idleTimeout <= 0 {
+			return m, nil
+		}
+		idle := time.Since(m.lastActivity)
+		if idle >= m.idleTimeout {
+			return m, m.quitIdle()
+		}
+		return m, checkIdle(m.idleTimeout - idle)
+
+	case tea.MouseMsg:
+		if cmd := m.handleClick(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case tea.FocusMsg:
+		m.focused = true
+		m.focusKnown = true
+
+	case tea.BlurMsg:
+		m.focused = false
+		m.focusKnown = true
+
+	case redrawViewportMsg:
+		// This message just forces a redraw of the viewport
+		m.updateViewportContent()
+
+	case tickMsg:
+		now := time.Time(msg)
+
+		// Keep ticking while paused, so resuming needs no new schedule
+		if m.paused {
+			m.tickDue = now.Add(pollInterval)
+			return m, tick(pollInterval)
+		}
 
-	case tickMsg:
 		// Refresh counter
 		m.refreshCount++
 
+		resumed := !m.tickDue.IsZero() && now.Sub(m.tickDue) > resumeGapThreshold
+
+		// Poll less often while rate limited, until Slack lets us back in
+		next := pollInterval
+		if wait := m.throttledUntil.Sub(now); wait > next {
+			next = wait
+		}
+		m.tickDue = now.Add(next)
+
 		// Schedule the next tick and fetch messages
-		cmds = append(cmds, tick())
-		cmds = append(cmds, fetchMessages(m.client, m.channelID, m.lastFetched))
+		cmds = append(cmds, tick(next))
+		if resumed {
+			// Woke up from sleep: check the connection, catching up on
+			// everything posted meanwhile below
+			cmds = append(cmds, revalidateConnection(m.client, m.channelID))
+		}
+		cmds = append(cmds, fetchMessages(m.client, m.channelID, m.lastFetched, m.config.FetchLimit))
 		return m, tea.Batch(cmds...)
 
-	case fetchMessagesMsg:
+	case snippetMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not upload the snippet: %w", msg.err))
+			return m, nil
+		}
+		m.setStatus("snippet uploaded")
+		return m, nil
+
+	case clipboardMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not copy: %w", msg.err))
+			return m, nil
+		}
+		m.setStatus(msg.status)
+		return m, nil
+
+	case profileMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not load the profile: %w", msg.err))
+			return m, nil
+		}
+		m.openOverlay("Profile", renderProfile(msg.profile))
+		return m, nil
+
+	case dateMsg:
+		return m, m.jumpToDate(msg)
+
+	case teamNameMsg:
+		m.teamName = string(msg)
+		return m, nil
+
+	case bookmarksMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not load the bookmarks: %w", msg.err))
+			return m, nil
+		}
+		if msg.channelID != m.channelID {
+			return m, nil
+		}
+		m.bookmarks = msg.bookmarks
+		m.openOverlay("Bookmarks", renderBookmarks(msg.bookmarks))
+		return m, nil
+
+	case uploadFileMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not upload %s: %w", msg.name, msg.err))
+			return m, nil
+		}
+		m.setStatus(fmt.Sprintf("%s uploaded", msg.name))
+		return m, nil
+
+	case linkedMessageMsg:
+		m.quoteLinked(msg)
+		return m, nil
+
+	case exportMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not export the messages: %w", msg.err))
+			return m, nil
+		}
+		m.setStatus(fmt.Sprintf("exported %d messages to %s", msg.count, msg.path))
+		return m, nil
+
+	case openURLMsg:
+		if msg.err != nil {
+			m.logError(msg.err)
+		}
+		return m, nil
+
+	case archiveMsg:
+		return m, m.archived(msg)
+
+	case crosspostMsg:
+		m.crossposted(msg)
+		return m, nil
+
+	case searchMsg:
+		m.showSearch(msg)
+		return m, nil
+
+	case channelInfoMsg:
+		m.updateChannelInfo(msg)
+		return m, nil
+
+	case rejoinMsg:
+		return m, m.rejoined(msg)
+
+	case leaveArchivedMsg:
+		m.repick = true
+		return m, tea.Quit
+
+	case memberMsg:
+		if msg.err != nil {
+			m.logError(msg.err)
+			return m, nil
+		}
+		m.setStatus(msg.status)
+		return m, nil
+
+	case dndRefreshMsg:
+		return m, fetchDnd(m.client)
+
+	case dndMsg:
+		m.updateDnd(msg)
+		if msg.scheduled {
+			return m, dndRefresh(dndRefreshInterval)
+		}
+		return m, nil
+
+	case customEmojiMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not load the custom emoji: %w", msg.err))
+			return m, nil
+		}
+		if m.emojiPicker != nil {
+			m.setEmojiNames(msg.names)
+			m.refreshEmojiPicker()
+		}
+		return m, nil
+
+	case reactionMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not react: %w", msg.err))
+			return m, nil
+		}
+		if msg.channelID == m.channelID {
+			m.addReaction(msg.ts, msg.name)
+			m.updateReactionPages()
+		}
+		m.setStatus(fmt.Sprintf("reacted with :%s:", msg.name))
+		return m, nil
+
+	case threadRefreshMsg:
+		return m, tea.Batch(m.refreshThreads(), fetchMentions(m.client, false))
+
+	case mentionsMsg:
+		if msg.err != nil {
+			if msg.show {
+				m.logError(fmt.Errorf("could not load mentions: %w", msg.err))
+			}
+			return m, nil
+		}
+		m.mentions = msg.mentions
+		if msg.show {
+			m.openOverlay("Activity", m.renderActivity())
+		}
+		return m, nil
+
+	case scopesMsg:
+		m.warnMissingScopes(msg)
+		return m, nil
+
+	case localPinMsg:
+		m.addLocalPin(msg)
+		return m, nil
+
+	case translationMsg:
+		m.addTranslation(msg)
+		return m, nil
+
+	case threadPreviewMsg:
+		// Failed previews stay empty rather than being retried
+		if msg.err == nil {
+			m.threadPreviews[msg.ts] = msg.preview
+			m.updateViewportContent()
+		}
+		return m, nil
+
+	case threadMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not load the thread: %w", msg.err))
+			return m, nil
+		}
+		if msg.channelID == m.channelID {
+			m.showThread(msg.messages)
+		}
+		return m, nil
+
+	case watchTickMsg:
+		return m, m.pollWatched()
+
+	case watchFetchMsg:
+		// Errors are retried on the next poll
+		if msg.err == nil {
+			m.recordWatched(msg.channelID, msg.messages)
+		}
+		return m, nil
+
+	case channelSwitchMsg:
+		if msg.err != nil {
+			m.logError(msg.err)
+			return m, nil
+		}
+		if msg.fromHistory {
+			m.channelHistoryIndex = msg.historyIndex
+		} else {
+			m.visitChannel(msg.channelID)
+		}
+		return m, m.switchChannel(msg.channelID, msg.channelName, msg.channel)
+
+	case quotedMessageMsg:
+		if msg.err != nil {
+			m.logError(msg.err)
+			return m, nil
+		}
+		m.showQuoted(msg.message)
+		return m, nil
+
+	case clearFlashMsg:
+		if m.flashTs == msg.ts {
+			m.flashTs = ""
+			m.updateViewportContent()
+		}
+		return m, nil
+
+	case fileContentMsg:
 		if msg.err != nil {
-			m.err = msg.err
+			m.logError(msg.err)
 			return m, nil
 		}
+		m.openOverlay(msg.file.Name, string(msg.content))
+		return m, nil
 
-		// If this is an immediate refresh after sending a message
-		if msg.messages == nil {
-			return m, fetchMessages(m.client, m.channelID, "")
+	case editorMsg:
+		if msg.err != nil {
+			m.logError(msg.err)
+			return m, nil
+		}
+		if strings.TrimSpace(msg.text) == "" {
+			m.setStatus("empty message, nothing to send")
+			return m, nil
+		}
+		if msg.snippet != nil {
+			m.input.Reset()
+			return m, m.uploadSnippet(*msg.snippet, msg.text)
+		}
+		// Large messages are loaded instead, for Enter to confirm
+		if msg.send && m.confirmLarge(msg.text) {
+			m.input.Reset()
+			m.discardStaged()
+			m.jumpToBottom()
+			return m, m.send(msg.text)
+		}
+		m.loadDraft(msg.text)
+		return m, nil
+
+	case deleteMessageMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not delete message: %w", msg.err))
+			return m, nil
+		}
+		if msg.channelID == m.channelID {
+			m.removeMessage(msg.ts)
 		}
+		m.setStatus("message deleted")
+		return m, nil
 
+	case updateMessageMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not edit message: %w", msg.err))
+			return m, nil
+		}
+		if msg.channelID == m.channelID {
+			for i := range m.messages {
+				if m.messages[i].id == msg.ts {
+					m.messages[i].message.Text = msg.text
+				}
+			}
+			m.updateViewportContent()
+		}
+		m.setStatus("message edited")
+		return m, nil
+
+	case savedListMsg, savedToggleMsg:
+		m.updateSaved(msg)
+		return m, nil
+
+	case resumeMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("resync after resume failed: %w", msg.err))
+			return m, nil
+		}
+		if msg.channel.ID == m.channelID && !msg.channel.IsIM {
+			m.channelName = msg.channel.Name
+			m.channel = msg.channel
+		}
+		m.setStatus("resynced after resume")
+		return m, nil
+
+	case fetchMessagesMsg:
+		// Ignore fetches for a channel we've since switched away from
+		if msg.channelID != m.channelID {
+			return m, nil
+		}
+
+		m.throttledUntil = msg.throttledUntil
+		if msg.err != nil {
+			if m.throttled() {
+				// Not fatal, the next poll is pushed back until the limit expires
+				return m, nil
+			}
+			// Keep showing what we have, the next tick retries
+			if m.lastFetchErr == nil {
+				m.logError(msg.err)
+			}
+			m.lastFetchErr = msg.err
+			m.reconnectIfStale(msg.err)
+			if reason, ok := channelGone(msg.err); ok {
+				return m, m.lostChannel(reason)
+			}
+			return m, nil
+		}
+		m.lastFetchErr = nil
+		m.goneReason = ""
+		m.connFailures = 0
+		if msg.limited {
+			m.historyLimited = true
+		}
+		if msg.hasOlder {
+			m.olderAvailable = true
+		}
+
+		m.recordWatched(msg.channelID, msg.messages)
 		if len(msg.messages) > 0 {
 			// Track if we've added any messages
 			messagesAdded := false
+			mentioned := false
+			scrolledUp := !m.viewport.AtBottom()
 
 			// Process new messages
 			for _, message := range msg.messages {
 				// Skip messages we've already processed
 				if m.messageIDs[message.Ts] {
+					if m.confirmSent(message) || m.updateKnown(message) {
+						messagesAdded = true
+					}
 					continue
 				}
 
-				ts, _ := strconv.ParseFloat(message.Ts, 64)
-				timestamp := time.Unix(int64(ts), 0)
-
-				username, err := m.client.UsernameForMessage(message)
-				if err != nil {
-					username = "unknown"
+				m.addMessage(message)
+				messagesAdded = true
+				if scrolledUp && !isFromSelf(message, m.selfID) {
+					m.unseenCount++
 				}
 
-				formattedText := fmt.Sprintf("%s %s: %s",
-					timeStyle.Render(timestamp.Format("15:04:05")),
-					usernameStyle.Render(username),
-					messageStyle.Render(message.Text),
-				)
-
-				m.messages = append(m.messages, formattedMessage{
-					text:      formattedText,
-					timestamp: timestamp,
-					id:        message.Ts,
-				})
+				if m.loaded && shouldNotify(message, m.selfID, m.channelID, m.config.notifyLevel(m.channelID)) {
+					mentioned = true
+				}
+			}
 
-				m.messageIDs[message.Ts] = true
-				messagesAdded = true
+			// Only alert when the user may not be looking at the terminal
+			if mentioned && m.config.MentionBell && !(m.focusKnown && m.focused) && !m.dndActive() {
+				cmds = append(cmds, bell(m.config, m.output))
 			}
 
 			if messagesAdded {
 				// Sort messages by timestamp
-				sort.Slice(m.messages, func(i, j int) bool {
-					return m.messages[i].timestamp.Before(m.messages[j].timestamp)
-				})
+				m.sortMessages()
 
-				// Update the last fetched timestamp
-				if len(msg.messages) > 0 {
-					m.lastFetched = msg.messages[0].Ts
+				// Update the last fetched timestamp, which refetching older
+				// messages, e.g. for their replies, mustn't move back
+				if latest := msg.messages[0].Ts; m.lastFetched == "" || parseTs(latest).After(parseTs(m.lastFetched)) {
+					m.lastFetched = latest
 				}
 
-				// Always update the viewport content when messages change
-				m.updateViewportContent()
+				// Update the viewport content when messages change, unless
+				// that has to wait until we stop typing
+				cmds = append(cmds, m.redraw(), m.fetchThreadPreviews(), m.translateMessages())
 			}
 		}
+		m.loaded = true
+
+		if m.pendingJump != "" {
+			cmds = append(cmds, m.resolvePendingJump())
+		}
 
 	case sendMessageMsg:
+		if msg.channelID != m.channelID {
+			return m, nil
+		}
 		if msg.err != nil {
-			m.err = msg.err
+			m.sendFailed(m.dropPending(msg.pendingID), msg.err)
+			return m, nil
+		}
+		m.lastFailedSend = ""
+		m.lastSentTs = msg.response.TS
+		m.lastSentText = msg.response.Message.Text
+		m.resolvePending(msg.pendingID, msg.response)
+		// Refresh until the sent message shows up in the history
+		return m, tea.Batch(
+			m.confirmSend(msg.response.TS, 0),
+			fetchPermalink(m.client, m.channelID, msg.response.TS),
+		)
+
+	case permalinkMsg:
+		// Nice to have, so failures aren't worth reporting
+		if msg.err == nil {
+			m.setStatusFor(fmt.Sprintf("sent: %s", msg.permalink), permalinkStatusDuration)
+		}
+		return m, nil
+
+	case composeIdleMsg:
+		return m, m.composeIdle()
+
+	case confirmSendMsg:
+		if msg.channelID != m.channelID || !m.awaitingConfirmation(msg.ts) {
+			return m, nil
+		}
+		if msg.attempt >= maxConfirmAttempts {
+			// Give up, the regular poll will catch up eventually
 			return m, nil
 		}
-		// Force a refresh of messages after sending
-		return m, fetchMessages(m.client, m.channelID, "")
+		return m, m.confirmSend(msg.ts, msg.attempt)
 	}
 
 	// Always update these components
 	m.input, tiCmd = m.input.Update(msg)
 	m.viewport, vpCmd = m.viewport.Update(msg)
 
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.lastKeystroke = time.Now()
+	}
+	// Catch up on redraws held while typing, e.g. once the input is cleared
+	if !m.composing() {
+		m.flushRedraw()
+	}
+
+	// Scrolling back down counts as having seen the new messages
+	if m.overlay == nil && m.viewport.AtBottom() {
+		m.unseenCount = 0
+	}
+
 	// Add in any other commands we've collected
 	cmds = append(cmds, tiCmd, vpCmd)
 
 	return m, tea.Batch(cmds...)
 }
 
+// setStatus shows a transient message in the header for a few seconds.
+func (m *model) setStatus(status string) {
+	m.setStatusFor(status, statusDuration)
+}
+
+func (m *model) setStatusFor(status string, d time.Duration) {
+	m.status = status
+	m.statusUntil = time.Now().Add(d)
+}
+
+func (m *model) throttled() bool {
+	return time.Now().Before(m.throttledUntil)
+}
+
+func (m *model) openOverlay(title, content string) {
+	prevOffset := m.viewport.YOffset
+	if m.overlay != nil {
+		prevOffset = m.overlay.prevOffset
+	}
+
+	m.overlay = &overlay{title: title, content: content, prevOffset: prevOffset}
+	m.viewport.SetContent(content)
+	m.viewport.GotoTop()
+}
+
+func (m *model) closeOverlay() {
+	prevOffset := m.overlay.prevOffset
+	m.overlay = nil
+	m.updateViewportContent()
+	m.viewport.SetYOffset(prevOffset)
+}
+
+// togglePolling pauses or resumes polling for new messages, catching up
+// straight away on resume.
+func (m *model) togglePolling() tea.Cmd {
+	m.paused = !m.paused
+	if m.paused {
+		m.setStatus("polling paused")
+		return nil
+	}
+
+	m.setStatus("polling resumed")
+	return fetchMessages(m.client, m.channelID, m.lastFetched, m.config.FetchLimit)
+}
+
+// jumpToBottom scrolls to the latest message, clearing the new messages
+// banner.
+func (m *model) jumpToBottom() {
+	m.viewport.GotoBottom()
+	m.unseenCount = 0
+}
+
+// scrollToMessage scrolls so the next (delta > 0) or previous message
+// starts at the top of the viewport.
+func (m *model) scrollToMessage(delta int) {
+	if m.overlay != nil {
+		return
+	}
+
+	top := m.viewport.YOffset
+	target := -1
+	for _, row := range m.messageRows {
+		if row < 0 {
+			continue
+		}
+		if delta > 0 && row > top && (target < 0 || row < target) {
+			target = row
+		}
+		if delta < 0 && row < top && row > target {
+			target = row
+		}
+	}
+
+	if target >= 0 {
+		m.viewport.SetYOffset(target)
+	}
+}
+
 func (m *model) updateViewportContent() {
-	var content strings.Builder
-	for _, msg := range m.messages {
-		content.WriteString(msg.text + "\n")
+	if m.overlay != nil {
+		m.viewport.SetContent(m.overlay.content)
+		return
 	}
 
-	// Show refresh count as a debugging aid
-	//content.WriteString(fmt.Sprintf("\n[Refreshed %d times]", m.refreshCount))
+	// Only follow new messages if we were already at the bottom, so reading
+	// older messages isn't interrupted
+	atBottom := m.viewport.AtBottom()
+
+	var content strings.Builder
+	m.messageRows = m.messageRows[:0]
+	m.messageLines = m.messageLines[:0]
+	row := 0
+	prevAuthor := ""
+	for i, msg := range m.messages {
+		if !m.visible(i) {
+			m.messageRows = append(m.messageRows, -1)
+			m.messageLines = append(m.messageLines, 0)
+			continue
+		}
+
+		rendered, ok := m.renderMembershipRun(i)
+		if !ok {
+			rendered = m.renderMessage(msg)
+		}
+		if m.avatars {
+			if msg.username == prevAuthor {
+				rendered = avatarPadding(msg.username) + " " + rendered
+			} else {
+				rendered = avatar(msg.username) + " " + rendered
+			}
+			prevAuthor = msg.username
+		}
+		if m.selecting && i == m.selected {
+			rendered = selectedStyle.Render("▶") + " " + rendered
+		}
+		if msg.id == m.flashTs {
+			rendered = flashStyle.Render("┃") + " " + rendered
+		}
+		if m.timestampShown(timestampRight) {
+			rendered = m.alignTimestamp(rendered, msg)
+		}
+		if m.alignSelf && m.ownMessage(msg) {
+			rendered = lipgloss.PlaceHorizontal(m.viewport.Width, lipgloss.Right, rendered)
+		}
+
+		lines := strings.Count(rendered, "\n") + 1
+		m.messageRows = append(m.messageRows, row)
+		m.messageLines = append(m.messageLines, lines)
+		row += lines
+		content.WriteString(rendered + "\n")
+	}
 
 	m.viewport.SetContent(content.String())
-	m.viewport.GotoBottom()
+	if m.selecting {
+		m.scrollToSelection()
+	} else if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// confirmSent marks a message we sent as confirmed once polling returns it,
+// taking the server's copy. Reports whether anything changed.
+func (m *model) confirmSent(message Message) bool {
+	for i := range m.messages {
+		if m.messages[i].id == message.Ts && m.messages[i].state == stateSent {
+			m.messages[i].message = message
+			m.messages[i].state = stateConfirmed
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *model) ownMessage(msg formattedMessage) bool {
+	return isFromSelf(msg.message, m.selfID)
+}
+
+// renderMessage builds the display line for a message from its structured
+// data, so display preferences can change without refetching.
+func (m *model) renderMessage(msg formattedMessage) string {
+	msg.message = applyRenderers(msg.message)
+
+	nameStyle := usernameStyle
+	if m.ownMessage(msg) {
+		nameStyle = m.selfStyle
+	}
+
+	textStyle := messageStyle
+	if m.handled[msg.message.Ts] {
+		textStyle = handledStyle
+	}
+
+	text, hidden := m.collapse(msg)
+	refs, focused := 0, m.focusedReference(msg)
+	line := fmt.Sprintf("%s: %s",
+		nameStyle.Render(highlight(m.fitUsername(msg.username), m.filterRe)),
+		renderMrkdwn(m.redact(text), func(text string) string {
+			return textStyle.Render(m.renderText(text, &refs, focused))
+		}),
+	)
+	if hidden > 0 {
+		line += "\n" + statusStyle.Render(fmt.Sprintf("… (%d more lines, select and press %s to expand)", hidden, m.keys.ToggleExpand.Help().Key))
+	}
+
+	switch msg.state {
+	case stateSending:
+		line += " " + statusStyle.Render("sending…")
+	case stateSent:
+		line += " " + statusStyle.Render("✓")
+	}
+
+	line += m.renderTranslation(msg.message)
+	line += m.renderFiles(msg.message.Files)
+	line += m.renderPreviews(msg.message)
+	line += m.renderThread(msg.message)
+
+	if prefix := m.config.botPrefix(msg.message); prefix != "" {
+		line = prefix + " " + line
+	}
+
+	if m.saved[savedKey(m.channelID, msg.id)] {
+		line = "★ " + line
+	}
+
+	if m.handled[msg.message.Ts] {
+		line = handledStyle.Render("✔") + " " + line
+	}
+
+	if m.timestampShown(timestampLeft) {
+		line = timeStyle.Render(msg.timestamp.Format("15:04:05")) + " " + line
+	}
+
+	return line
 }
 
 // Modified to be more robust in fetching messages
-func fetchMessages(client *SlackClient, channelID, since string) tea.Cmd {
+func fetchMessages(client *SlackClient, channelID, since string, limit int) tea.Cmd {
 	return func() tea.Msg {
-		// If no since timestamp is provided, fetch the most recent messages
-		limit := 20
+		start := time.Now()
 		history, err := client.History(channelID, since, "", limit)
+		elapsed := time.Since(start).Round(time.Millisecond)
 		if err != nil {
-			return fetchMessagesMsg{nil, err}
+			client.log.Printf("fetching %s failed after %s: %v", channelID, elapsed, err)
+			return fetchMessagesMsg{channelID, nil, err, client.ThrottledUntil(), false, false}
 		}
 
-		return fetchMessagesMsg{history.Messages, nil}
+		// The message we fetch since is returned again
+		fresh := 0
+		for _, msg := range history.Messages {
+			if msg.Ts != since {
+				fresh++
+			}
+		}
+		// Most polls find nothing, which is only worth logging when debugging
+		if fresh == 0 {
+			client.debugf("fetched no new messages from %s in %s", channelID, elapsed)
+		} else {
+			client.log.Printf("fetched %d new messages from %s in %s", fresh, channelID, elapsed)
+		}
+
+		// Only fetching the latest messages tells about older ones
+		hasOlder := since == "" && history.HasMore && !history.Limited()
+		return fetchMessagesMsg{channelID, history.Messages, nil, client.ThrottledUntil(), history.Limited(), hasOlder}
 	}
 }
 
@@ -404,50 +1851,310 @@ func (m model) View() string {
 		return "Initializing..."
 	}
 
-	if m.err != nil {
-		return fmt.Sprintf("Error: %s\nPress Ctrl+C to quit.", m.err)
+	channelLabel := m.channel.Marker() + m.channelName
+	if m.channel != nil && m.channel.IsShared {
+		channelLabel += " ⇄"
+	}
+	if m.channel != nil && m.channel.IsArchived {
+		channelLabel += " (archived)"
+	}
+	if m.config.notifyLevel(m.channelID) == notifyNone {
+		channelLabel += " 🔕"
+	}
+	// Leave room for the topic and status next to long channel names
+	channelLabel = truncate(m.teamName, m.viewport.Width/6) + " › " + truncate(channelLabel, m.viewport.Width/2)
+	channelHeader := m.theme.header.Render(channelLabel)
+	if m.channel != nil && m.channel.Topic.Value != "" {
+		topic := strings.Join(strings.Fields(m.channel.Topic.Value), " ")
+		channelHeader += " " + statusStyle.Render(truncate(topic, m.viewport.Width/4))
+	}
+	if m.overlay != nil {
+		channelHeader = m.theme.header.Render(m.overlay.title) + " " + statusStyle.Render("Esc to close")
+	}
+	if m.filter != "" && m.overlay == nil {
+		channelHeader += " " + statusStyle.Render(fmt.Sprintf("filter: %s", m.filter))
+	}
+	if m.reactFilter != "" && m.overlay == nil {
+		channelHeader += " " + statusStyle.Render(fmt.Sprintf("reactions: %s", m.reactFilter))
+	}
+	if m.unhandledOnly && m.overlay == nil {
+		channelHeader += " " + statusStyle.Render("unhandled only")
+	}
+	if m.config.VimMode && m.overlay == nil {
+		mode := "-- INSERT --"
+		if m.selecting {
+			mode = "-- NORMAL --"
+		}
+		channelHeader += " " + statusStyle.Render(mode)
+	}
+	if m.dndActive() {
+		channelHeader += " " + statusStyle.Render("DND")
+	}
+	if m.paused {
+		channelHeader += " " + statusStyle.Render("paused")
+	}
+	if loaded := m.loadedStatus(); loaded != "" && m.overlay == nil {
+		channelHeader += " " + statusStyle.Render(loaded)
+	}
+	if undone := m.undoneStatus(); undone != "" && m.overlay == nil {
+		channelHeader += " " + statusStyle.Render(undone)
+	}
+	if watched := m.watchedStatus(); watched != "" && m.overlay == nil && !m.split {
+		channelHeader += " " + statusStyle.Render(watched)
+	}
+	if m.throttled() {
+		channelHeader += " " + statusStyle.Render("rate limited, slowing refresh")
+	} else if m.goneReason != "" {
+		channelHeader += " " + errorStyle.Render(m.goneReason+" (/rejoin or /switch)")
+	} else if m.lastFetchErr != nil {
+		channelHeader += " " + errorStyle.Render("last update failed, retrying")
+	}
+	if m.status != "" && time.Now().Before(m.statusUntil) {
+		channelHeader += " " + statusStyle.Render(m.status)
+	} else if reactions := m.reactionStatus(); reactions != "" && m.selecting {
+		channelHeader += " " + statusStyle.Render(reactions)
 	}
-
-	channelHeader := channelStyle.Render(fmt.Sprintf("#%s", m.channelName))
 	messagesView := m.viewport.View()
+	if bar := m.scrollbar(); bar != "" {
+		messagesView = lipgloss.JoinHorizontal(lipgloss.Top, messagesView, bar)
+	}
+	if pins := m.renderLocalPins(); pins != "" {
+		messagesView = pins + "\n" + messagesView
+	}
+	above, below := m.renderPanes()
+	messagesView += below
+
+	inputField := m.theme.input.Render(m.input.View())
+
+	historyIndicator := m.charCounter() + m.refreshPulse()
+	if m.editingTs != "" {
+		historyIndicator += " [Editing: Enter to save, Esc to cancel]"
+	} else if m.browsingHist {
+		historyIndicator += fmt.Sprintf(" [History: %d/%d]", m.historyIndex+1, len(m.history))
+	}
+
+	unseenBanner := ""
+	if m.staged != "" {
+		unseenBanner = m.theme.banner.Render(m.stagedBanner())
+	} else if m.unseenCount > 0 && m.overlay == nil {
+		noun := "messages"
+		if m.unseenCount == 1 {
+			noun = "message"
+		}
+		unseenBanner = m.theme.banner.Render(fmt.Sprintf("↓ %d new %s (press %s to jump)", m.unseenCount, noun, m.keys.JumpBottom.Help().Key))
+	} else if m.historyLimited && m.overlay == nil {
+		unseenBanner = statusStyle.Render("Older messages are hidden by the workspace's plan or retention settings")
+	}
+
+	// A header wrapping onto a second line would push everything down
+	channelHeader = truncate(channelHeader, m.viewport.Width)
 
-	inputField := inputStyle.Render(m.input.View())
+	return fmt.Sprintf("%s%s\n\n%s\n%s\n%s%s", above, channelHeader, messagesView, unseenBanner, inputField, historyIndicator)
+}
 
-	historyIndicator := ""
-	if m.browsingHist {
-		historyIndicator = fmt.Sprintf(" [History: %d/%d]", m.historyIndex+1, len(m.history))
+// refreshPulse alternates on every poll, to show refreshing is alive. It
+// stays off while polls are failing.
+func (m model) refreshPulse() string {
+	if !m.config.RefreshIndicator || m.lastFetchErr != nil {
+		return ""
 	}
 
-	return fmt.Sprintf("%s\n\n%s\n\n%s%s", channelHeader, messagesView, inputField, historyIndicator)
+	if m.refreshCount%2 == 0 {
+		return statusStyle.Render(" ○")
+	}
+	return statusStyle.Render(" ●")
+}
+
+// loadedStatus says how many messages are loaded when the channel has
+// older ones, which Slack doesn't count, so there's no total to show.
+func (m model) loadedStatus() string {
+	if !m.olderAvailable || m.historyLimited {
+		return ""
+	}
+
+	loaded := 0
+	for _, msg := range m.messages {
+		if msg.message.Ts != "" {
+			loaded++
+		}
+	}
+	return fmt.Sprintf("showing %d, older ones with /date", loaded)
+}
+
+// charCounter shows how much of the message length limit the input uses,
+// in the warning color once it gets close.
+func (m model) charCounter() string {
+	n := utf8.RuneCountInString(m.input.Value())
+	if n == 0 {
+		return ""
+	}
+
+	counter := fmt.Sprintf(" %d/%d", n, maxMessageLength)
+	if n >= maxMessageLength*9/10 {
+		return errorStyle.Render(counter)
+	}
+	return timeStyle.Render(counter)
 }
 
 func main() {
-	// Use io.Discard for the logger
-	logger := log.New(io.Discard, "", 0)
+	idleTimeout := flag.Duration("idle-timeout", 0, "quit after this long without a keystroke, e.g. 30m (0 means never)")
+	avatars := flag.Bool("avatars", false, "show author initials before each group of messages")
+	alignSelf := flag.Bool("align-self", false, "align your own messages to the right")
+	mouse := flag.Bool("mouse", false, "enable the mouse: the wheel scrolls and clicking a timestamp copies the message's permalink (terminal text selection then needs Shift)")
+	redact := flag.Bool("redact", false, "mask secrets and email addresses in the messages shown, e.g. for screen sharing")
+	debug := flag.Bool("debug", false, "enable debugging aids, like copying the raw JSON of the selected message with J")
+	logPath := flag.String("log", "", "write a log of fetches and API calls to this file")
+	logLevel := flag.String("log-level", "info", "log level, info or debug (debug also logs empty fetches and API responses)")
+	showVersion := flag.Bool("version", false, "print the version and build info, and exit")
+	watch := flag.String("watch", "", "comma separated channels to poll in the background, e.g. '#ops,#alerts'")
+	split := flag.Bool("split", false, "show the --watch channels in panes along with the current one, Tab moves between them")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: slack-chat [flags] <team> [channelID|#channel-name]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionInfo())
+		return
+	}
+
+	// The team picks the workspace credentials, so it can't be chosen later
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
 
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: slack-chat <team> <channelID>")
+	if *logLevel != "info" && *logLevel != "debug" {
+		fmt.Fprintf(os.Stderr, "Invalid log level %q, use info or debug\n", *logLevel)
 		os.Exit(1)
 	}
 
-	team := os.Args[1]
-	channelID := os.Args[2]
+	team := flag.Arg(0)
+
+	// Without --log, log to io.Discard
+	logger := log.New(io.Discard, "", 0)
+	if *logPath != "" {
+		f, err := os.OpenFile(*logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening the log: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		logger = log.New(f, "", log.LstdFlags|log.Lmicroseconds)
+	}
 
 	client, err := NewClient(team, logger)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
 		os.Exit(1)
 	}
+	client.debugLog = *logLevel == "debug"
 
-	initialModel, err := initialModel(client, channelID)
+	config, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating model: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
+	client.SetTimeouts(
+		time.Duration(config.RequestTimeout)*time.Second,
+		time.Duration(config.DialTimeout)*time.Second,
+		time.Duration(config.KeepAlive)*time.Second,
+	)
 
-	p := tea.NewProgram(initialModel, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+	var channelID string
+	if flag.NArg() < 2 {
+		// Progress output would garble the picker
+		client.progress = io.Discard
+		channelID, err = pickChannel(client, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error picking a channel: %v\n", err)
+			os.Exit(1)
+		}
+		if channelID == "" {
+			return
+		}
+	} else {
+		channelID, err = client.ResolveChannel(flag.Arg(1))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving channel: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	watched, err := resolveWatched(client, *watch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving watched channels: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Progress output would garble the TUI from here on
+	client.progress = io.Discard
+
+	var redactions []*regexp.Regexp
+	if *redact {
+		redactions, err = compileRedactions(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if config.PostAs != nil {
+		isBot, err := client.IsBot()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking the token type: %v\n", err)
+			os.Exit(1)
+		}
+		if !isBot {
+			fmt.Fprintln(os.Stderr, "Error: post_as in the config needs a bot token")
+			os.Exit(1)
+		}
+	}
+
+	output := &terminalOutput{File: os.Stdout}
+	for {
+		initialModel, err := initialModel(client, config, channelID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating model: %v\n", err)
+			os.Exit(1)
+		}
+		initialModel.idleTimeout = *idleTimeout
+		initialModel.avatars = *avatars
+		initialModel.alignSelf = *alignSelf
+		initialModel.watched = watched
+		if *split {
+			if err := initialModel.enableSplit(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		initialModel.redactions = redactions
+		initialModel.debug = *debug
+		initialModel.output = output
+
+		options := []tea.ProgramOption{tea.WithAltScreen(), tea.WithReportFocus(), tea.WithOutput(output)}
+		if *mouse {
+			options = append(options, tea.WithMouseCellMotion())
+		}
+		p := tea.NewProgram(initialModel, options...)
+		result, err := p.Run()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !result.(model).repick {
+			return
+		}
+
+		channelID, err = pickChannel(client, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error picking a channel: %v\n", err)
+			os.Exit(1)
+		}
+		if channelID == "" {
+			return
+		}
+	}
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type memberMsg struct {
+	status string
+	err    error
+}
+
+// memberCommand adds the user in args to the channel, or removes them when
+// invite is false.
+func (m *model) memberCommand(args string, invite bool) tea.Cmd {
+	if args == "" {
+		if invite {
+			m.setStatus("usage: /invite @user")
+		} else {
+			m.setStatus("usage: /kick @user")
+		}
+		return nil
+	}
+
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		userID, err := client.ResolveUser(args)
+		if err != nil {
+			return memberMsg{err: err}
+		}
+
+		name, err := client.UsernameForID(userID)
+		if err != nil {
+			name = userID
+		}
+
+		if invite {
+			if err := client.InviteUser(channelID, userID); err != nil {
+				return memberMsg{err: fmt.Errorf("could not invite %s: %w", name, err)}
+			}
+			return memberMsg{status: fmt.Sprintf("invited %s", name)}
+		}
+
+		if err := client.RemoveUser(channelID, userID); err != nil {
+			return memberMsg{err: fmt.Errorf("could not remove %s: %w", name, err)}
+		}
+		return memberMsg{status: fmt.Sprintf("removed %s from the channel", name)}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel returns a model on channel C1 of a fake workspace where we
+// are alice (U1) and bob (U2) is someone else, with the config and state
+// kept in a temporary directory.
+func newTestModel(t *testing.T) (*fakeSlack, model) {
+	t.Helper()
+	return newTestModelWithConfig(t, defaultConfig())
+}
+
+// newTestModelWithConfig is newTestModel with the given settings.
+func newTestModelWithConfig(t *testing.T, config *Config) (*fakeSlack, model) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home+"/config")
+	t.Setenv("XDG_STATE_HOME", home+"/state")
+
+	fake, client := newFakeSlack(t)
+	fake.respond("auth.test", ok(map[string]any{"user_id": "U1"}))
+	fake.respond("conversations.info", ok(map[string]any{
+		"channel": map[string]any{"id": "C1", "name": "ops", "is_channel": true},
+	}))
+	fake.respond("users.list", ok(map[string]any{
+		"members": []map[string]any{{"id": "U1", "name": "alice"}, {"id": "U2", "name": "bob"}},
+	}))
+
+	m, err := initialModel(client, config, "C1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, m
+}
+
+// update runs msg through the model, dropping the commands it returns.
+func update(m model, msg tea.Msg) model {
+	updated, _ := m.Update(msg)
+	return updated.(model)
+}
+
+// fetched is the result of fetching messages from C1, newest first.
+func fetched(messages ...Message) fetchMessagesMsg {
+	return fetchMessagesMsg{channelID: "C1", messages: messages}
+}
+
+func TestReconnectDeduplicatesMessages(t *testing.T) {
+	_, m := newTestModel(t)
+
+	first := Message{User: "U2", Text: "first", Ts: "1700000001.000100"}
+	second := Message{User: "U2", Text: "second", Ts: "1700000002.000200"}
+	third := Message{User: "U2", Text: "third", Ts: "1700000003.000300"}
+
+	m = update(m, fetched(second, first))
+
+	// A full refresh after reconnecting delivers the messages we have again,
+	// along with the one missed while disconnected
+	m = update(m, fetched(third, second, first))
+	m = update(m, fetched(third))
+
+	var got []string
+	for _, msg := range m.messages {
+		got = append(got, msg.message.Text)
+	}
+	if len(got) != 3 || got[0] != "first" || got[1] != "second" || got[2] != "third" {
+		t.Errorf("got messages %q, want each once in order", got)
+	}
+	if m.lastFetched != third.Ts {
+		t.Errorf("last fetched %s, want %s", m.lastFetched, third.Ts)
+	}
+}
+
+func TestSentMessageShowsBeforeThePollConfirmsIt(t *testing.T) {
+	_, m := newTestModel(t)
+
+	pendingID := m.addPending("hello")
+	if len(m.messages) != 1 || m.messages[0].state != stateSending {
+		t.Fatalf("got %+v, want the message shown as sending", m.messages)
+	}
+
+	sent := Message{User: "U1", Text: "hello", Ts: "1700000001.000100"}
+	m = update(m, sendMessageMsg{channelID: "C1", pendingID: pendingID, response: &SendMessageResponse{TS: sent.Ts, Message: sent}})
+	if len(m.messages) != 1 || m.messages[0].id != sent.Ts || m.messages[0].state != stateSent {
+		t.Fatalf("got %+v, want the message shown as sent", m.messages)
+	}
+
+	m = update(m, fetched(sent))
+	if len(m.messages) != 1 {
+		t.Fatalf("got %d messages, want the sent one once", len(m.messages))
+	}
+	if m.messages[0].state != stateConfirmed {
+		t.Errorf("got state %v, want confirmed once polled", m.messages[0].state)
+	}
+}
+
+func TestFailedFetchKeepsMessages(t *testing.T) {
+	_, m := newTestModel(t)
+	m = update(m, tea.WindowSizeMsg{Width: 200, Height: 40})
+
+	first := Message{User: "U2", Text: "first", Ts: "1700000001.000100"}
+	second := Message{User: "U2", Text: "second", Ts: "1700000002.000200"}
+	m = update(m, fetched(first))
+
+	m = update(m, fetchMessagesMsg{channelID: "C1", err: errors.New("connection reset by peer")})
+	if len(m.messages) != 1 {
+		t.Fatalf("got %d messages after a failed fetch, want the one loaded", len(m.messages))
+	}
+	if !strings.Contains(m.View(), "last update failed, retrying") {
+		t.Error("the header doesn't say the update failed")
+	}
+
+	m = update(m, fetched(second))
+	if len(m.messages) != 2 {
+		t.Fatalf("got %d messages, want both once fetching works again", len(m.messages))
+	}
+	if m.lastFetchErr != nil || strings.Contains(m.View(), "last update failed") {
+		t.Error("the failure is still reported after a successful fetch")
+	}
+}
+
+func TestParseTs(t *testing.T) {
+	for _, tc := range []struct {
+		ts   string
+		want int64 // microseconds
+	}{
+		{"1700000001.000100", 1700000001000100},
+		{"1700000001.5", 1700000001500000},
+		{"1700000001", 1700000001000000},
+	} {
+		if got := parseTs(tc.ts).UnixMicro(); got != tc.want {
+			t.Errorf("parseTs(%q) = %d, want %d", tc.ts, got, tc.want)
+		}
+	}
+}
+
+func TestSameSecondMessagesKeepTheirOrder(t *testing.T) {
+	_, m := newTestModel(t)
+
+	// Out of order, all within the same second
+	m = update(m, fetched(
+		Message{User: "U2", Text: "third", Ts: "1700000001.900000"},
+		Message{User: "U2", Text: "first", Ts: "1700000001.000100"},
+	))
+	m = update(m, fetched(
+		Message{User: "U2", Text: "fourth", Ts: "1700000001.900001"},
+		Message{User: "U2", Text: "second", Ts: "1700000001.000200"},
+	))
+
+	var got []string
+	for _, msg := range m.messages {
+		got = append(got, msg.message.Text)
+	}
+	if strings.Join(got, " ") != "first second third fourth" {
+		t.Errorf("got messages in order %q", got)
+	}
+}
+
+func TestDndRefreshFailureLoggedOnce(t *testing.T) {
+	_, m := newTestModel(t)
+
+	failed := dndMsg{err: errors.New("missing_scope"), scheduled: true}
+	m = update(m, failed)
+	m = update(m, failed)
+	if len(m.errorLog) != 1 {
+		t.Fatalf("logged %d errors for two failed refreshes, want 1", len(m.errorLog))
+	}
+	if got := m.errorLog[0].err.Error(); !strings.Contains(got, "could not read") {
+		t.Errorf("logged %q", got)
+	}
+
+	m = update(m, dndMsg{err: errors.New("snooze_failed")})
+	if len(m.errorLog) != 2 || !strings.Contains(m.errorLog[1].err.Error(), "could not update") {
+		t.Errorf("a failed /dnd isn't logged as such: %v", m.errorLog)
+	}
+}
+
+func TestRedrawWaitsWhileTyping(t *testing.T) {
+	config := defaultConfig()
+	config.PauseWhileTyping = true
+	_, m := newTestModelWithConfig(t, config)
+	m = update(m, tea.WindowSizeMsg{Width: 200, Height: 40})
+
+	first := Message{User: "U2", Text: "first", Ts: "1700000001.000100"}
+	second := Message{User: "U2", Text: "second", Ts: "1700000002.000200"}
+	m = update(m, fetched(first))
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hi")})
+
+	m = update(m, fetched(second, first))
+	if strings.Contains(m.viewport.View(), "second") {
+		t.Fatal("redrew while typing")
+	}
+
+	updated, cmd := m.Update(composeIdleMsg{})
+	m = updated.(model)
+	if cmd == nil {
+		t.Error("stopped checking for a pause while still typing")
+	}
+	if strings.Contains(m.viewport.View(), "second") {
+		t.Fatal("redrew on the idle check while still typing")
+	}
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(m.viewport.View(), "second") {
+		t.Error("the held redraw wasn't applied once the message was sent")
+	}
+	if m.needsRedraw {
+		t.Error("a redraw is still pending after sending")
+	}
+}
+
+func TestMissingScopesStatus(t *testing.T) {
+	_, m := newTestModel(t)
+
+	m = update(m, scopesMsg{missing: []string{"chat:write", "search:read"}})
+	if m.status != "token lacks 2 scopes, f2 for details" {
+		t.Errorf("got status %q", m.status)
+	}
+	if len(m.errorLog) != 2 {
+		t.Errorf("logged %d errors, want one per missing scope", len(m.errorLog))
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Screen rows above the messages: the header and a blank line
+const messagesTop = 2
+
+// messageAtRow returns the message rendered at row of the viewport's
+// content.
+func (m *model) messageAtRow(row int) (formattedMessage, bool) {
+	for i := range m.messageRows {
+		if m.messageRows[i] < 0 {
+			continue
+		}
+		if row >= m.messageRows[i] && row < m.messageRows[i]+m.messageLines[i] {
+			return m.messages[i], true
+		}
+	}
+	return formattedMessage{}, false
+}
+
+// handleClick copies the permalink of a message when its timestamp is
+// clicked.
+func (m *model) handleClick(msg tea.MouseMsg) tea.Cmd {
+	if m.overlay != nil || msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+		return nil
+	}
+	if !m.timestampShown(timestampLeft) && !m.timestampShown(timestampRight) {
+		return nil
+	}
+
+	y := msg.Y - messagesTop - len(m.localPins)
+	if y < 0 || y >= m.viewport.Height {
+		return nil
+	}
+	clicked, ok := m.messageAtRow(m.viewport.YOffset + y)
+	if !ok || clicked.message.Ts == "" {
+		return nil
+	}
+
+	// The timestamp isn't always in the first column, e.g. with avatars
+	lines := strings.Split(m.viewport.View(), "\n")
+	if y >= len(lines) {
+		return nil
+	}
+	line := stripANSI(lines[y])
+	stamp := clicked.timestamp.Format("15:04:05")
+	i := strings.Index(line, stamp)
+	if i < 0 {
+		return nil
+	}
+	start := lipgloss.Width(line[:i])
+	if msg.X < start || msg.X >= start+len(stamp) {
+		return nil
+	}
+
+	return copyPermalink(m.client, m.output, m.channelID, clicked.message.Ts)
+}
+
+func copyPermalink(client *SlackClient, out io.Writer, channelID, ts string) tea.Cmd {
+	return func() tea.Msg {
+		link, err := client.Permalink(channelID, ts)
+		if err != nil {
+			return clipboardMsg{err: fmt.Errorf("could not get the permalink: %w", err)}
+		}
+		return copyToClipboard(out, link, "permalink copied")()
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var quoteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("63"))
+
+// Slack sends > escaped in message text
+var quotePrefixes = []string{"&gt;", ">"}
+
+// renderMrkdwn renders the block-level mrkdwn of a message: blockquotes
+// get a bar on the left and list items are indented. Code blocks are left
+// alone. style is applied to the text of every line.
+func renderMrkdwn(text string, style func(string) string) string {
+	lines := strings.Split(text, "\n")
+	inCode, quoteRest := false, false
+
+	for i, line := range lines {
+		if strings.Count(line, "```")%2 == 1 {
+			inCode = !inCode
+			lines[i] = style(line)
+			continue
+		}
+		if inCode {
+			lines[i] = style(line)
+			continue
+		}
+
+		// >>> quotes everything up to the end of the message
+		if rest, ok := trimQuote(line, 3); ok {
+			quoteRest = true
+			line = rest
+		}
+		if rest, ok := trimQuote(line, 1); ok || quoteRest {
+			lines[i] = quoteStyle.Render("▎") + " " + renderListItem(rest, style)
+			continue
+		}
+
+		lines[i] = renderListItem(line, style)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// trimQuote removes n quote markers from the start of line, reporting
+// whether they were there.
+func trimQuote(line string, n int) (string, bool) {
+	rest := line
+	for i := 0; i < n; i++ {
+		found := false
+		for _, prefix := range quotePrefixes {
+			if strings.HasPrefix(rest, prefix) {
+				rest = strings.TrimPrefix(rest, prefix)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return line, false
+		}
+	}
+
+	return strings.TrimPrefix(rest, " "), true
+}
+
+// renderListItem indents bullet list items, one more level for items that
+// are themselves indented.
+func renderListItem(line string, style func(string) string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	for _, marker := range []string{"• ", "◦ ", "- ", "* "} {
+		if !strings.HasPrefix(trimmed, marker) {
+			continue
+		}
+
+		bullet := "  • "
+		if trimmed != line || marker == "◦ " {
+			bullet = "    ◦ "
+		}
+		return bullet + style(strings.TrimPrefix(trimmed, marker))
+	}
+
+	return style(line)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMrkdwn(t *testing.T) {
+	bar := quoteStyle.Render("▎") + " "
+	plain := func(text string) string { return text }
+
+	for _, tc := range []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			"quotes, lists and code together",
+			"&gt; quoted\nplain\n```\n&gt; not a quote\n- not a list\n```\n• item\n  - nested",
+			[]string{bar + "quoted", "plain", "```", "&gt; not a quote", "- not a list", "```", "  • item", "    ◦ nested"},
+		},
+		{
+			"quote to the end",
+			"intro\n&gt;&gt;&gt; first\nsecond\n- third",
+			[]string{"intro", bar + "first", bar + "second", bar + "  • third"},
+		},
+		{
+			"list in a quote",
+			"> * one\n> * two",
+			[]string{bar + "  • one", bar + "  • two"},
+		},
+		{
+			"code on one line",
+			"run ```make``` first\n- then this",
+			[]string{"run ```make``` first", "  • then this"},
+		},
+	} {
+		got := strings.Split(renderMrkdwn(tc.text, plain), "\n")
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("%s: got\n%q\nwant\n%q", tc.name, got, tc.want)
+		}
+	}
+}
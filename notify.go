@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Notification levels for a channel
+const (
+	notifyAll      = "all"
+	notifyMentions = "mentions"
+	notifyNone     = "none"
+)
+
+// isFromSelf reports whether we wrote msg, which must never alert us,
+// whatever the feature. selfID is empty when it couldn't be looked up.
+func isFromSelf(msg Message, selfID string) bool {
+	return selfID != "" && msg.User == selfID
+}
+
+// shouldNotify reports whether a new message should alert the user given
+// the channel's notification level. Our own messages never do.
+func shouldNotify(msg Message, selfID, channelID, level string) bool {
+	if isFromSelf(msg, selfID) {
+		return false
+	}
+
+	switch level {
+	case notifyAll:
+		return true
+	case notifyNone:
+		return false
+	default:
+		return isMention(msg, selfID, channelID)
+	}
+}
+
+// isMention reports whether a message should alert the user: it mentions
+// them directly, pings the whole channel, or arrives in a DM.
+func isMention(msg Message, selfID, channelID string) bool {
+	if isFromSelf(msg, selfID) {
+		return false
+	}
+
+	if strings.HasPrefix(channelID, "D") {
+		return true
+	}
+
+	if selfID != "" && strings.Contains(msg.Text, "<@"+selfID+">") {
+		return true
+	}
+
+	for _, broadcast := range []string{"<!here>", "<!channel>", "<!everyone>"} {
+		if strings.Contains(msg.Text, broadcast) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// terminalOutput is the terminal the program renders to. Writes are
+// serialized, so the bell goes out between frames instead of in the middle
+// of one. It stays an *os.File for Bubble Tea to find the terminal size.
+type terminalOutput struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (o *terminalOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.File.Write(p)
+}
+
+func (o *terminalOutput) WriteString(s string) (int, error) {
+	return o.Write([]byte(s))
+}
+
+// bell alerts the user by playing the configured sound file, falling back
+// to ringing the terminal bell on out when there's no sound or it can't be
+// played.
+func bell(cfg *Config, out io.Writer) tea.Cmd {
+	return func() tea.Msg {
+		if cfg.MentionSound != "" && playSound(cfg.MentionSound) == nil {
+			return nil
+		}
+
+		io.WriteString(out, "\a")
+		return nil
+	}
+}
+
+// playSound plays file until it ends, which is fine from a tea.Cmd and
+// reaps the player.
+func playSound(file string) error {
+	player := "paplay"
+	if runtime.GOOS == "darwin" {
+		player = "afplay"
+	}
+
+	return exec.Command(player, file).Run()
+}
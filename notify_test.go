@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestOwnMessagesNeverNotify(t *testing.T) {
+	for _, text := range []string{"hello", "<@U1> note to self", "<!here> deploying", "<!channel> done"} {
+		own := Message{User: "U1", Text: text}
+		other := Message{User: "U2", Text: text}
+
+		for _, channelID := range []string{"C1", "D1"} {
+			for _, level := range []string{notifyAll, notifyMentions, notifyNone} {
+				if shouldNotify(own, "U1", channelID, level) {
+					t.Errorf("our own %q in %s notifies at level %s", text, channelID, level)
+				}
+			}
+			if isMention(own, "U1", channelID) {
+				t.Errorf("our own %q in %s is a mention", text, channelID)
+			}
+			if !shouldNotify(other, "U1", channelID, notifyAll) {
+				t.Errorf("%q from someone else in %s doesn't notify", text, channelID)
+			}
+		}
+	}
+
+	// Without our ID nothing can be told apart, better noisy than silent
+	if !shouldNotify(Message{User: "U1", Text: "hello"}, "", "D1", notifyMentions) {
+		t.Error("a DM doesn't notify when our ID is unknown")
+	}
+}
+
+func TestOwnMentionsArentActivity(t *testing.T) {
+	fake, m := newTestModel(t)
+	fake.respond("search.messages", ok(map[string]any{
+		"messages": map[string]any{
+			"matches": []map[string]any{
+				{"user": "U2", "text": "<@U1> can you look?", "ts": "1700000002.000200", "channel": map[string]any{"id": "C2", "name": "dev"}},
+				{"user": "U1", "text": "<@U1> reminder", "ts": "1700000001.000100", "channel": map[string]any{"id": "C2", "name": "dev"}},
+			},
+			"total": 2,
+		},
+	}))
+
+	msg := fetchMentions(m.client, false)().(mentionsMsg)
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	if len(msg.mentions) != 1 || msg.mentions[0].User != "U2" {
+		t.Errorf("got mentions %+v, want only bob's", msg.mentions)
+	}
+}
+
+func TestOwnMessagesArentUnread(t *testing.T) {
+	_, m := newTestModel(t)
+	m.watched = []*watchedChannel{{id: "C2", name: "alerts", seen: make(map[string]bool)}}
+
+	m.recordWatched("C2", []Message{{User: "U2", Text: "before", Ts: "1700000001.000100"}})
+	m.recordWatched("C2", []Message{
+		{User: "U1", Text: "mine", Ts: "1700000003.000300"},
+		{User: "U2", Text: "theirs", Ts: "1700000002.000200"},
+	})
+	if unread := m.watchedChannel("C2").unread; unread != 1 {
+		t.Errorf("watched channel has %d unread, want only the one from someone else", unread)
+	}
+
+	// Nor new below the messages scrolled up to
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 10})
+	var history []Message
+	for i := 20; i > 0; i-- {
+		history = append(history, Message{User: "U2", Text: "old", Ts: fmt.Sprintf("1700000000.%06d", i)})
+	}
+	m = update(m, fetched(history...))
+	m.viewport.GotoTop()
+
+	m = update(m, fetched(Message{User: "U1", Text: "mine", Ts: "1700000010.000100"}))
+	m = update(m, fetched(Message{User: "U2", Text: "theirs", Ts: "1700000011.000100"}))
+	if m.unseenCount != 1 {
+		t.Errorf("%d messages unseen, want only the one from someone else", m.unseenCount)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var pickerKeys = struct {
+	Up, Down, Pick, Cancel key.Binding
+}{
+	Up:     binding("previous channel", "up", "ctrl+p"),
+	Down:   binding("next channel", "down", "ctrl+n"),
+	Pick:   binding("open channel", "enter"),
+	Cancel: binding("quit", "esc", "ctrl+c"),
+}
+
+type pickerEntry struct {
+	id    string
+	label string
+}
+
+type pickerDetailsMsg struct {
+	id      string
+	channel *Channel
+	err     error
+}
+
+type pickerChannelsMsg struct {
+	entries []pickerEntry
+	err     error
+}
+
+// pickerModel lets us choose the channel to open when none was given on
+// the command line.
+type pickerModel struct {
+	client  *SlackClient
+	filter  textinput.Model
+	entries []pickerEntry
+	matches []pickerEntry
+	index   int
+	height  int
+	width   int
+	loaded  bool
+	err     error
+
+	// Topic and member count of the channels highlighted so far, fetched
+	// lazily to keep loading fast. A nil entry is still loading or failed.
+	details map[string]*Channel
+
+	chosen string
+}
+
+// pickChannel shows the channel picker, returning the ID of the chosen
+// channel, or an empty string if we quit without choosing.
+func pickChannel(client *SlackClient, config *Config) (string, error) {
+	ti := textinput.New()
+	ti.Placeholder = "Type to filter..."
+	ti.Focus()
+	config.applyPrompt(&ti)
+
+	picker := pickerModel{client: client, filter: ti, details: make(map[string]*Channel)}
+	result, err := tea.NewProgram(picker, tea.WithAltScreen()).Run()
+	if err != nil {
+		return "", err
+	}
+
+	picker = result.(pickerModel)
+	return picker.chosen, picker.err
+}
+
+func loadPickerChannels(client *SlackClient) tea.Cmd {
+	return func() tea.Msg {
+		channels, err := client.ListChannels()
+		if err != nil {
+			return pickerChannelsMsg{err: fmt.Errorf("could not list channels: %w", err)}
+		}
+		dms, err := client.ListDMs()
+		if err != nil {
+			return pickerChannelsMsg{err: fmt.Errorf("could not list DMs: %w", err)}
+		}
+
+		var entries []pickerEntry
+		for _, group := range [][]Channel{channels, dms} {
+			start := len(entries)
+			for i := range group {
+				ch := &group[i]
+				entries = append(entries, pickerEntry{ch.ID, ch.Marker() + client.ChannelDisplayName(ch)})
+			}
+			// Channels first, then DMs, each sorted by name
+			sort.Slice(entries[start:], func(i, j int) bool {
+				return entries[start+i].label < entries[start+j].label
+			})
+		}
+
+		return pickerChannelsMsg{entries: entries}
+	}
+}
+
+func (p pickerModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, loadPickerChannels(p.client))
+}
+
+func (p pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.height = msg.Height
+		p.width = msg.Width
+
+	case pickerChannelsMsg:
+		if msg.err != nil {
+			p.err = msg.err
+			return p, tea.Quit
+		}
+		p.entries = msg.entries
+		p.loaded = true
+		p.applyFilter()
+		return p, p.fetchDetails()
+
+	case pickerDetailsMsg:
+		// Without details the picker still works, so errors are ignored
+		if msg.err == nil {
+			p.details[msg.id] = msg.channel
+		}
+		return p, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, pickerKeys.Cancel):
+			return p, tea.Quit
+		case key.Matches(msg, pickerKeys.Pick):
+			if p.index < len(p.matches) {
+				p.chosen = p.matches[p.index].id
+				return p, tea.Quit
+			}
+			return p, nil
+		case key.Matches(msg, pickerKeys.Up):
+			if p.index > 0 {
+				p.index--
+			}
+			return p, p.fetchDetails()
+		case key.Matches(msg, pickerKeys.Down):
+			if p.index < len(p.matches)-1 {
+				p.index++
+			}
+			return p, p.fetchDetails()
+		}
+	}
+
+	var cmd tea.Cmd
+	prev := p.filter.Value()
+	p.filter, cmd = p.filter.Update(msg)
+	if p.filter.Value() != prev {
+		p.applyFilter()
+		cmd = tea.Batch(cmd, p.fetchDetails())
+	}
+
+	return p, cmd
+}
+
+func (p *pickerModel) applyFilter() {
+	term := strings.ToLower(p.filter.Value())
+
+	p.matches = p.matches[:0]
+	for _, entry := range p.entries {
+		if strings.Contains(strings.ToLower(entry.label), term) {
+			p.matches = append(p.matches, entry)
+		}
+	}
+	p.index = 0
+}
+
+// fetchDetails loads the details of the highlighted channel, unless
+// they've been asked for already.
+func (p pickerModel) fetchDetails() tea.Cmd {
+	if p.index >= len(p.matches) {
+		return nil
+	}
+	id := p.matches[p.index].id
+	if _, ok := p.details[id]; ok {
+		return nil
+	}
+	p.details[id] = nil
+
+	client := p.client
+	return func() tea.Msg {
+		ch, err := client.ChannelInfo(id)
+		return pickerDetailsMsg{id, ch, err}
+	}
+}
+
+// detailLine describes the highlighted channel, by its topic or purpose
+// and member count.
+func (p pickerModel) detailLine() string {
+	if p.index >= len(p.matches) {
+		return ""
+	}
+	ch := p.details[p.matches[p.index].id]
+	if ch == nil {
+		return ""
+	}
+
+	var parts []string
+	description := ch.Topic.Value
+	if description == "" {
+		description = ch.Purpose.Value
+	}
+	if description != "" {
+		parts = append(parts, strings.Join(strings.Fields(description), " "))
+	}
+	if ch.NumMembers > 0 {
+		parts = append(parts, fmt.Sprintf("%d members", ch.NumMembers))
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+func (p pickerModel) View() string {
+	var b strings.Builder
+	b.WriteString(channelStyle.Render("Pick a channel") + " " + statusStyle.Render("Enter to open, Esc to quit"))
+	b.WriteString("\n" + p.filter.View() + "\n\n")
+
+	if !p.loaded {
+		b.WriteString(statusStyle.Render("Loading channels…"))
+		return b.String()
+	}
+	if len(p.matches) == 0 {
+		b.WriteString(statusStyle.Render("No matching channels"))
+		return b.String()
+	}
+
+	// Keep the highlighted channel in view, leaving room for its details
+	rows := p.height - 6
+	if rows < 1 {
+		rows = 10
+	}
+	start := 0
+	if p.index >= rows {
+		start = p.index - rows + 1
+	}
+	end := start + rows
+	if end > len(p.matches) {
+		end = len(p.matches)
+	}
+
+	for i := start; i < end; i++ {
+		if i == p.index {
+			b.WriteString(selectedStyle.Render("▶ "+p.matches[i].label) + "\n")
+		} else {
+			b.WriteString("  " + p.matches[i].label + "\n")
+		}
+	}
+
+	if detail := p.detailLine(); detail != "" {
+		b.WriteString("\n" + statusStyle.Render(truncate(detail, p.width)))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Widest a link preview's description gets before it's cut
+const maxPreviewWidth = 100
+
+var (
+	previewBarStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	previewTitleStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// renderPreviews shows the pages linked in a message, as unfurled by Slack,
+// with their title, description and site.
+func (m *model) renderPreviews(msg Message) string {
+	if !m.config.LinkPreviews {
+		return ""
+	}
+
+	width := m.viewport.Width - 4
+	if width > maxPreviewWidth {
+		width = maxPreviewWidth
+	}
+
+	var out strings.Builder
+	bar := "\n" + previewBarStyle.Render("┃") + " "
+	for _, a := range msg.Attachments {
+		// Linked messages are shown by jumping to them instead
+		if a.IsMsgUnfurl || a.Title == "" {
+			continue
+		}
+
+		out.WriteString(bar + previewTitleStyle.Render(truncate(m.redact(a.Title), width)))
+		if description, _, _ := strings.Cut(strings.TrimSpace(a.Text), "\n"); description != "" {
+			out.WriteString(bar + truncate(m.redact(description), width))
+		}
+		if site := previewSite(a); site != "" {
+			out.WriteString(bar + statusStyle.Render(site))
+		}
+	}
+
+	return out.String()
+}
+
+// previewSite names the site a preview comes from, falling back to the
+// domain of the link.
+func previewSite(a Attachment) string {
+	if a.ServiceName != "" {
+		return a.ServiceName
+	}
+
+	link := a.OriginalURL
+	if link == "" {
+		link = a.TitleLink
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
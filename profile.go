@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var profileStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("63")).
+	Padding(0, 1)
+
+type profileMsg struct {
+	profile *Profile
+	err     error
+}
+
+// showAuthorProfile looks up the profile of the selected message's author.
+func (m *model) showAuthorProfile() tea.Cmd {
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	userID := selected.message.User
+	if userID == "" {
+		m.setStatus("bots and integrations have no profile")
+		return nil
+	}
+
+	client := m.client
+	return func() tea.Msg {
+		profile, err := client.UserProfile(userID)
+		return profileMsg{profile, err}
+	}
+}
+
+// renderProfile renders a compact card with the profile fields we were
+// allowed to see.
+func renderProfile(p *Profile) string {
+	name := p.RealName
+	if name == "" {
+		name = p.Name
+	}
+
+	lines := []string{usernameStyle.Render(name)}
+	if handle := p.Details.DisplayName; handle != "" && handle != name {
+		lines = append(lines, timeStyle.Render("@"+handle))
+	} else if p.Name != name {
+		lines = append(lines, timeStyle.Render("@"+p.Name))
+	}
+
+	field := func(label, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", timeStyle.Render(label), value))
+		}
+	}
+
+	field("Title: ", p.Details.Title)
+	field("Status:", strings.TrimSpace(p.Details.StatusEmoji+" "+p.Details.StatusText))
+	if p.TZ != "" {
+		local := time.Now().UTC().Add(time.Duration(p.TZOffset) * time.Second)
+		field("Time:  ", fmt.Sprintf("%s (%s)", local.Format("15:04"), p.TZLabel))
+	}
+	field("Email: ", p.Details.Email)
+	field("Phone: ", p.Details.Phone)
+	if p.IsBot {
+		lines = append(lines, statusStyle.Render("bot user"))
+	}
+
+	return profileStyle.Render(strings.Join(lines, "\n"))
+}
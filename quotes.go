@@ -0,0 +1,114 @@
+package main
+
+import (
+	"regexp"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// How long a message we jumped to stays highlighted
+const flashDuration = 2 * time.Second
+
+var flashStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true)
+
+// permalinkRe matches message permalinks, which encode the timestamp
+// without its dot, e.g. /archives/C0123ABCD/p1700000000123456.
+var permalinkRe = regexp.MustCompile(`/archives/([CGD][A-Z0-9]+)/p(\d{10})(\d{6})`)
+
+type quotedMessageMsg struct {
+	message *Message
+	err     error
+}
+
+type clearFlashMsg struct {
+	ts string
+}
+
+// quotedMessage returns the channel and timestamp of the message msg
+// replies to or quotes, if any. Thread replies point to their parent, and
+// shared or linked messages to the original.
+func quotedMessage(channelID string, msg Message) (string, string, bool) {
+	if msg.ThreadTs != "" && msg.ThreadTs != msg.Ts {
+		return channelID, msg.ThreadTs, true
+	}
+
+	for _, a := range msg.Attachments {
+		if channelID, ts, ok := parseSlackLink(a.FromURL); ok {
+			return channelID, ts, true
+		}
+	}
+
+	if m := permalinkRe.FindStringSubmatch(msg.Text); m != nil {
+		return m[1], m[2] + "." + m[3], true
+	}
+
+	return "", "", false
+}
+
+// jumpToQuoted selects the message quoted by the selected one when it is
+// loaded, or fetches it to show it in an overlay otherwise.
+func (m *model) jumpToQuoted() tea.Cmd {
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	channelID, ts, ok := quotedMessage(m.channelID, selected.message)
+	if !ok {
+		m.setStatus("this message doesn't quote another one")
+		return nil
+	}
+
+	if channelID == m.channelID {
+		for i := range m.messages {
+			if m.messages[i].id != ts {
+				continue
+			}
+			if !m.visible(i) {
+				m.setStatus("the quoted message is hidden by the filter")
+				return nil
+			}
+			return m.jumpTo(i)
+		}
+	}
+
+	m.setStatus("loading the quoted message…")
+	client := m.client
+	return func() tea.Msg {
+		msg, err := client.Message(channelID, ts)
+		return quotedMessageMsg{msg, err}
+	}
+}
+
+// jumpTo selects message i, centers it in the viewport and highlights it
+// for a moment.
+func (m *model) jumpTo(i int) tea.Cmd {
+	ts := m.messages[i].id
+	m.selected = i
+	m.flashTs = ts
+	m.selectionChanged()
+
+	if m.messageRows[i] >= 0 {
+		m.viewport.SetYOffset(m.messageRows[i] - (m.viewport.Height-m.messageLines[i])/2)
+	}
+
+	return tea.Tick(flashDuration, func(time.Time) tea.Msg {
+		return clearFlashMsg{ts}
+	})
+}
+
+func (m *model) showQuoted(msg *Message) {
+	username, err := m.client.UsernameForMessage(*msg)
+	if err != nil {
+		username = "unknown"
+	}
+
+	m.openOverlay("Quoted message", m.renderMessage(formattedMessage{
+		message:   *msg,
+		username:  username,
+		timestamp: parseTs(msg.Ts),
+		id:        msg.Ts,
+	}))
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// reactionSummary lists who reacted with what to the selected message, one
+// reaction per entry.
+func (m *model) reactionSummary(msg Message) []string {
+	entries := make([]string, 0, len(msg.Reactions))
+	for _, reaction := range msg.Reactions {
+		names := make([]string, 0, len(reaction.Users))
+		for _, id := range reaction.Users {
+			name, err := m.client.UsernameForID(id)
+			if err != nil {
+				name = id
+			}
+			names = append(names, name)
+		}
+
+		// Slack only lists some of the users on popular reactions
+		if others := reaction.Count - len(reaction.Users); others > 0 {
+			names = append(names, fmt.Sprintf("%d more", others))
+		}
+
+		entries = append(entries, fmt.Sprintf(":%s: %s", reaction.Name, strings.Join(names, ", ")))
+	}
+
+	return entries
+}
+
+// paginate groups entries into pages no wider than width.
+func paginate(entries []string, width int) []string {
+	var pages []string
+	var page string
+	for _, entry := range entries {
+		if page != "" && lipgloss.Width(page)+3+lipgloss.Width(entry) > width {
+			pages = append(pages, page)
+			page = ""
+		}
+		if page != "" {
+			page += " · "
+		}
+		page += entry
+	}
+
+	if page != "" {
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// updateReactionPages recomputes the reactions shown for the selected
+// message.
+func (m *model) updateReactionPages() {
+	m.reactionPages = nil
+	m.reactionPage = 0
+
+	selected, ok := m.selectedMessage()
+	if !ok || len(selected.message.Reactions) == 0 {
+		return
+	}
+
+	width := m.viewport.Width - 30
+	if width < 20 {
+		width = 20
+	}
+	m.reactionPages = paginate(m.reactionSummary(selected.message), width)
+}
+
+func (m *model) nextReactionPage() {
+	if len(m.reactionPages) > 0 {
+		m.reactionPage = (m.reactionPage + 1) % len(m.reactionPages)
+	}
+}
+
+func (m *model) reactionStatus() string {
+	if len(m.reactionPages) == 0 {
+		return ""
+	}
+
+	status := m.reactionPages[m.reactionPage]
+	if len(m.reactionPages) > 1 {
+		status += fmt.Sprintf(" (%d/%d, R for more)", m.reactionPage+1, len(m.reactionPages))
+	}
+	return status
+}
+
+type reactionMsg struct {
+	channelID string
+	ts        string
+	name      string
+	err       error
+}
+
+// ack reacts to the latest message with the configured emoji, without
+// having to select it first.
+func (m *model) ack() tea.Cmd {
+	name := strings.Trim(m.config.AckEmoji, ":")
+	if name == "" {
+		name = "+1"
+	}
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		// Skip messages still being sent
+		if !m.visible(i) || m.messages[i].message.Ts == "" {
+			continue
+		}
+
+		return react(m.client, m.channelID, m.messages[i].message.Ts, name)
+	}
+
+	m.setStatus("no message to react to")
+	return nil
+}
+
+func react(client *SlackClient, channelID, ts, name string) tea.Cmd {
+	return func() tea.Msg {
+		err := client.AddReaction(channelID, ts, name)
+		return reactionMsg{channelID, ts, name, err}
+	}
+}
+
+// addReaction shows our reaction on a message until the next refresh
+// brings Slack's copy.
+func (m *model) addReaction(ts, name string) {
+	for i := range m.messages {
+		if m.messages[i].id != ts {
+			continue
+		}
+
+		msg := &m.messages[i].message
+		for j := range msg.Reactions {
+			if msg.Reactions[j].Name == name {
+				msg.Reactions[j].Count++
+				msg.Reactions[j].Users = append(msg.Reactions[j].Users, m.selfID)
+				return
+			}
+		}
+		msg.Reactions = append(msg.Reactions, Reaction{Name: name, Count: 1, Users: []string{m.selfID}})
+		return
+	}
+}
+
+// undoneStatus counts the loaded messages nobody has marked with the
+// configured done reaction yet, for triage channels.
+func (m *model) undoneStatus() string {
+	if m.config.DoneEmoji == "" {
+		return ""
+	}
+
+	undone := 0
+	for _, msg := range m.messages {
+		// Messages still being sent can't have reactions yet
+		if msg.message.Ts == "" {
+			continue
+		}
+		if matchesReactFilter(msg.message, "!"+m.config.DoneEmoji) {
+			undone++
+		}
+	}
+	if undone == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d without :%s:", undone, m.config.DoneEmoji)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultRedactPatterns mask common secret formats: Slack, GitHub and AWS
+// credentials, bearer tokens, private keys and email addresses.
+var defaultRedactPatterns = []string{
+	`xox[abposr]-[A-Za-z0-9-]+`,
+	`gh[pousr]_[A-Za-z0-9]{36,}`,
+	`github_pat_[A-Za-z0-9_]{22,}`,
+	`AKIA[0-9A-Z]{16}`,
+	`(?i)bearer\s+[A-Za-z0-9._~+/-]+=*`,
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+}
+
+// compileRedactions compiles the default patterns and the ones in the
+// config.
+func compileRedactions(cfg *Config) ([]*regexp.Regexp, error) {
+	patterns := append(append([]string{}, defaultRedactPatterns...), cfg.RedactPatterns...)
+
+	redactions := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		redactions = append(redactions, re)
+	}
+
+	return redactions, nil
+}
+
+// redact masks everything matching the redaction patterns in text that's
+// about to be shown. The messages themselves are left untouched.
+func (m *model) redact(text string) string {
+	for _, re := range m.redactions {
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			return strings.Repeat("█", utf8.RuneCountInString(match))
+		})
+	}
+	return text
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	referenceStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	focusedReferenceStyle = referenceStyle.Reverse(true)
+)
+
+// referenceRe matches user and channel references in message text, e.g.
+// <@U0123ABCD> or <#C0123ABCD|general>.
+var referenceRe = regexp.MustCompile(`<([@#])([A-Z0-9]+)(?:\|([^>]*))?>`)
+
+type reference struct {
+	channel bool // a channel, otherwise a user
+	id      string
+	label   string // channel name, when Slack included it
+}
+
+func references(text string) []reference {
+	var refs []reference
+	for _, match := range referenceRe.FindAllStringSubmatch(text, -1) {
+		refs = append(refs, reference{channel: match[1] == "#", id: match[2], label: match[3]})
+	}
+	return refs
+}
+
+// renderText renders a line of message text: user and channel references
+// become names, and keywords and the filter term are highlighted. next
+// counts the references rendered so far in the message, so the focused one
+// can be told apart.
+func (m *model) renderText(text string, next *int, focused int) string {
+	var out strings.Builder
+	last := 0
+	for _, span := range referenceRe.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(m.plainText(text[last:span[0]]))
+		last = span[1]
+
+		ref := reference{channel: text[span[2]:span[3]] == "#", id: text[span[4]:span[5]]}
+		if span[6] >= 0 {
+			ref.label = text[span[6]:span[7]]
+		}
+
+		style := referenceStyle
+		if *next == focused {
+			style = focusedReferenceStyle
+		}
+		*next++
+
+		out.WriteString(style.Render(m.referenceName(ref)))
+	}
+	out.WriteString(m.plainText(text[last:]))
+
+	return out.String()
+}
+
+func (m *model) plainText(text string) string {
+	return m.keywords.render(text, func(text string) string {
+		return highlight(text, m.filterRe)
+	})
+}
+
+func (m *model) referenceName(ref reference) string {
+	if ref.channel {
+		if ref.label != "" {
+			return "#" + ref.label
+		}
+		return "#" + ref.id
+	}
+
+	name, err := m.client.UsernameForID(ref.id)
+	if err != nil {
+		name = ref.id
+	}
+	return "@" + name
+}
+
+// focusedReference returns the index of the focused reference in msg, or
+// -1 if none is.
+func (m *model) focusedReference(msg formattedMessage) int {
+	if selected, ok := m.selectedMessage(); ok && selected.id == msg.id {
+		return m.refIndex
+	}
+	return -1
+}
+
+// nextReference focuses the next reference of the selected message, going
+// back to none after the last one.
+func (m *model) nextReference() {
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return
+	}
+
+	refs := references(selected.message.Text)
+	if len(refs) == 0 {
+		m.setStatus("no channel or user references in this message")
+		return
+	}
+
+	m.refIndex++
+	if m.refIndex >= len(refs) {
+		m.refIndex = -1
+	}
+	m.updateViewportContent()
+}
+
+// openReference switches to the focused channel reference or shows the
+// profile of the focused user. Reports false when no reference is focused.
+func (m *model) openReference() (tea.Cmd, bool) {
+	selected, ok := m.selectedMessage()
+	if !ok || m.refIndex < 0 {
+		return nil, false
+	}
+
+	refs := references(selected.message.Text)
+	if m.refIndex >= len(refs) {
+		return nil, false
+	}
+
+	ref := refs[m.refIndex]
+	if ref.channel {
+		m.setStatus("switching channel…")
+		return joinChannel(m.client, ref.id), true
+	}
+
+	client := m.client
+	return func() tea.Msg {
+		profile, err := client.UserProfile(ref.id)
+		return profileMsg{profile, err}
+	}, true
+}
@@ -0,0 +1,32 @@
+package main
+
+// MessageRenderer transforms the text of messages before they're shown,
+// e.g. to translate them or shorten URLs. Renderers registered with
+// RegisterRenderer run in order after the built-in one, each getting the
+// message with the text the previous one returned.
+type MessageRenderer interface {
+	Render(Message) string
+}
+
+// builtinRenderer shows messages as Slack sent them.
+type builtinRenderer struct{}
+
+func (builtinRenderer) Render(msg Message) string {
+	return msg.Text
+}
+
+var renderers = []MessageRenderer{builtinRenderer{}}
+
+// RegisterRenderer adds a renderer after the ones already registered.
+// Call it from an init function, before the UI starts.
+func RegisterRenderer(r MessageRenderer) {
+	renderers = append(renderers, r)
+}
+
+// applyRenderers returns msg with its text transformed by every renderer.
+func applyRenderers(msg Message) Message {
+	for _, r := range renderers {
+		msg.Text = r.Render(msg)
+	}
+	return msg
+}
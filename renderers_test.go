@@ -0,0 +1,44 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// linkShortener is an example renderer, showing links by their host.
+type linkShortener struct{}
+
+var linkPattern = regexp.MustCompile(`<https?://([^/|>]+)[^|>]*>`)
+
+func (linkShortener) Render(msg Message) string {
+	return linkPattern.ReplaceAllString(msg.Text, "<$1>")
+}
+
+// shouting upper-cases messages, to tell whether it ran after another.
+type shouting struct{}
+
+func (shouting) Render(msg Message) string {
+	return strings.ToUpper(msg.Text)
+}
+
+func TestApplyRenderers(t *testing.T) {
+	defer func(registered []MessageRenderer) { renderers = registered }(renderers)
+
+	msg := Message{User: "U2", Text: "see <https://example.com/runbooks/deploy?step=2>", Ts: "1700000001.000100"}
+
+	if got := applyRenderers(msg); got.Text != msg.Text {
+		t.Errorf("the built-in renderer changed the text to %q", got.Text)
+	}
+
+	RegisterRenderer(linkShortener{})
+	RegisterRenderer(shouting{})
+
+	got := applyRenderers(msg)
+	if got.Text != "SEE <EXAMPLE.COM>" {
+		t.Errorf("got %q, want the link shortened and then upper-cased", got.Text)
+	}
+	if got.User != msg.User || got.Ts != msg.Ts {
+		t.Errorf("renderers changed more than the text: %+v", got)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A failed send counts as having gone through after all when the same text
+// of ours shows up posted this long before it failed, or any time after
+const sendSkew = time.Minute
+
+// sendFailed keeps the text of a message Slack didn't take, to be retried.
+func (m *model) sendFailed(text string, err error) {
+	m.logError(fmt.Errorf("could not send message: %w", err))
+	if text == "" {
+		return
+	}
+
+	m.lastFailedSend = text
+	m.lastFailedAt = time.Now()
+	m.setStatus(fmt.Sprintf("send failed, press %s to retry", m.keys.RetrySend.Help().Key))
+}
+
+// retrySend sends the last failed message again, unless polling shows it
+// went through after all, e.g. when only the response was lost.
+func (m *model) retrySend() tea.Cmd {
+	text := m.lastFailedSend
+	if text == "" {
+		m.setStatus("no failed message to retry")
+		return nil
+	}
+	m.lastFailedSend = ""
+
+	since := m.lastFailedAt.Add(-sendSkew)
+	for _, msg := range m.messages {
+		if msg.message.Ts != "" && m.ownMessage(msg) && msg.message.Text == text && msg.timestamp.After(since) {
+			m.setStatus("the message was sent after all")
+			return nil
+		}
+	}
+
+	m.jumpToBottom()
+	return m.send(text)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type savedListMsg struct {
+	items []SavedItem
+	err   error
+
+	// show opens the saved items overlay once loaded
+	show bool
+}
+
+type savedToggleMsg struct {
+	channelID string
+	ts        string
+	saved     bool
+	err       error
+}
+
+func savedKey(channelID, ts string) string {
+	return channelID + "/" + ts
+}
+
+func listSaved(client *SlackClient, show bool) tea.Cmd {
+	return func() tea.Msg {
+		items, err := client.ListSaved()
+		return savedListMsg{items, err, show}
+	}
+}
+
+// toggleSaved saves the selected message, or removes it from the saved
+// items if it was already saved.
+func (m *model) toggleSaved() tea.Cmd {
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	client, channelID, ts := m.client, m.channelID, selected.id
+	save := !m.saved[savedKey(channelID, ts)]
+
+	return func() tea.Msg {
+		var err error
+		if save {
+			err = client.SaveMessage(channelID, ts)
+		} else {
+			err = client.RemoveSaved(channelID, ts)
+		}
+		return savedToggleMsg{channelID, ts, save, err}
+	}
+}
+
+func (m *model) updateSaved(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case savedListMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not load saved items: %w", msg.err))
+			return
+		}
+
+		m.saved = make(map[string]bool)
+		for _, item := range msg.items {
+			m.saved[savedKey(item.ChannelID, item.Ts)] = true
+		}
+
+		if msg.show {
+			m.openOverlay("Saved items", m.renderSaved(msg.items))
+		} else {
+			m.updateViewportContent()
+		}
+
+	case savedToggleMsg:
+		if msg.err != nil {
+			m.logError(fmt.Errorf("could not update saved items: %w", msg.err))
+			return
+		}
+
+		key := savedKey(msg.channelID, msg.ts)
+		if msg.saved {
+			m.saved[key] = true
+			m.setStatus("message saved")
+		} else {
+			delete(m.saved, key)
+			m.setStatus("message removed from saved items")
+		}
+		m.updateViewportContent()
+	}
+}
+
+func (m *model) renderSaved(items []SavedItem) string {
+	if len(items) == 0 {
+		return "No saved messages."
+	}
+
+	var out strings.Builder
+	for _, item := range items {
+		text := "(message not available)"
+		if item.Message != nil {
+			username, err := m.client.UsernameForMessage(*item.Message)
+			if err != nil {
+				username = "unknown"
+			}
+			text = fmt.Sprintf("%s: %s", usernameStyle.Render(username), m.redact(item.Message.Text))
+		}
+
+		out.WriteString(fmt.Sprintf("%s %s\n", timeStyle.Render(item.ChannelID), text))
+	}
+
+	return out.String()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Scopes the token needs, and what doesn't work without each of them
+var requiredScopes = []struct {
+	scope, feature string
+}{
+	{"channels:read", "looking up channels"},
+	{"users:read", "showing who wrote messages"},
+	{"chat:write", "sending messages"},
+	{"reactions:write", "reacting to messages"},
+	{"search:read", "/search and /mentions"},
+	{"dnd:read", "silencing the mention bell during Do Not Disturb"},
+}
+
+type scopesMsg struct {
+	missing []string
+	err     error
+}
+
+// historyScope is the scope needed to read the messages of channel.
+func historyScope(channel *Channel) string {
+	switch {
+	case channel == nil:
+		return "channels:history"
+	case channel.IsIM:
+		return "im:history"
+	case channel.IsMpIM:
+		return "mpim:history"
+	case channel.IsPrivate:
+		return "groups:history"
+	}
+	return "channels:history"
+}
+
+// checkScopes looks for scopes the token lacks on startup, to explain
+// what won't work before it fails with a cryptic error.
+func checkScopes(client *SlackClient, channel *Channel) tea.Cmd {
+	required := []string{historyScope(channel)}
+	for _, s := range requiredScopes {
+		required = append(required, s.scope)
+	}
+
+	return func() tea.Msg {
+		missing, err := client.CheckScopes(required)
+		return scopesMsg{missing, err}
+	}
+}
+
+func (m *model) warnMissingScopes(msg scopesMsg) {
+	if errors.Is(msg.err, errScopesUnknown) {
+		// Browser sessions can do whatever the user can
+		m.client.debugf("not checking scopes: %v", msg.err)
+		return
+	}
+	if msg.err != nil {
+		m.client.log.Printf("could not check the token's scopes: %v", msg.err)
+		return
+	}
+	if len(msg.missing) == 0 {
+		return
+	}
+
+	features := make(map[string]string)
+	for _, s := range requiredScopes {
+		features[s.scope] = s.feature
+	}
+	for _, scope := range msg.missing {
+		feature, ok := features[scope]
+		if !ok {
+			feature = "reading this channel"
+		}
+		m.logError(fmt.Errorf("the token lacks the %s scope, needed for %s", scope, feature))
+	}
+
+	m.setStatus(fmt.Sprintf("token lacks %d scopes, %s for details", len(msg.missing), m.keys.ErrorLog.Help().Key))
+}
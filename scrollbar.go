@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	scrollTrackStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	scrollThumbStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+)
+
+// messagesWidth is the width left for the messages, minus the scrollbar
+// when enabled.
+func (m *model) messagesWidth(width int) int {
+	if m.config.Scrollbar {
+		return width - 1
+	}
+	return width
+}
+
+// scrollbar renders a column as high as the viewport, with a thumb
+// showing which part of the messages is in view. It's empty when they
+// all fit.
+func (m model) scrollbar() string {
+	height := m.viewport.Height
+	total := m.viewport.TotalLineCount()
+	if !m.config.Scrollbar || height <= 0 || total <= height {
+		return ""
+	}
+
+	thumb := height * height / total
+	if thumb < 1 {
+		thumb = 1
+	}
+	top := int(m.viewport.ScrollPercent() * float64(height-thumb))
+
+	rows := make([]string, height)
+	for i := range rows {
+		if i >= top && i < top+thumb {
+			rows[i] = scrollThumbStyle.Render("┃")
+		} else {
+			rows[i] = scrollTrackStyle.Render("│")
+		}
+	}
+	return strings.Join(rows, "\n")
+}
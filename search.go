@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Results shown per page by /search
+const searchPageSize = 20
+
+var searchKeys = struct {
+	NextPage, PrevPage key.Binding
+}{
+	NextPage: binding("next page of results", "n", "right"),
+	PrevPage: binding("previous page of results", "p", "left"),
+}
+
+type searchMsg struct {
+	query   string
+	results *SearchResults
+	err     error
+}
+
+// search is the query /search shows results for, and the page loaded.
+type search struct {
+	query   string
+	results *SearchResults
+}
+
+func (s *search) title() string {
+	return "Search: " + s.query
+}
+
+// searchCommand handles "/search <query>", which lists the messages
+// matching query across all channels, a page at a time.
+func (m *model) searchCommand(args string) tea.Cmd {
+	if args == "" {
+		m.setStatus("usage: /search <query>")
+		return nil
+	}
+
+	m.search = &search{query: args}
+	return m.searchPage(1)
+}
+
+func (m *model) searchPage(page int) tea.Cmd {
+	m.setStatus(fmt.Sprintf("searching for %s…", m.search.query))
+	client, query := m.client, m.search.query
+	return func() tea.Msg {
+		results, err := client.SearchMessages(query, searchPageSize, page)
+		return searchMsg{query, results, err}
+	}
+}
+
+func (m *model) showSearch(msg searchMsg) {
+	if msg.err != nil {
+		m.logError(fmt.Errorf("could not search for %s: %w", msg.query, msg.err))
+		return
+	}
+	// A newer search took over
+	if m.search == nil || m.search.query != msg.query {
+		return
+	}
+
+	m.search.results = msg.results
+	m.setStatus("")
+	m.openOverlay(m.search.title(), m.renderSearch())
+}
+
+// handleSearchKey pages through the results while they're shown,
+// reporting whether the key was used.
+func (m *model) handleSearchKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.search == nil || m.search.results == nil || m.overlay == nil || m.overlay.title != m.search.title() {
+		return nil, false
+	}
+
+	results := m.search.results
+	switch {
+	case key.Matches(msg, searchKeys.NextPage):
+		if results.Page < results.Pages {
+			return m.searchPage(results.Page + 1), true
+		}
+		m.setStatus("this is the last page")
+		return nil, true
+	case key.Matches(msg, searchKeys.PrevPage):
+		if results.Page > 1 {
+			return m.searchPage(results.Page - 1), true
+		}
+		m.setStatus("this is the first page")
+		return nil, true
+	}
+
+	return nil, false
+}
+
+func (m *model) renderSearch() string {
+	results := m.search.results
+	if len(results.Matches) == 0 {
+		return "No matching messages."
+	}
+
+	var out strings.Builder
+	out.WriteString(statusStyle.Render(fmt.Sprintf("%d matches, page %d of %d", results.Total, results.Page, results.Pages)) + "\n\n")
+
+	first := (results.Page-1)*searchPageSize + 1
+	query := highlightPattern(m.search.query)
+	for i, match := range results.Matches {
+		username, err := m.client.UsernameForMessage(match.Message)
+		if err != nil {
+			username = "unknown"
+		}
+
+		out.WriteString(fmt.Sprintf("%s %s %s %s: %s\n",
+			timeStyle.Render(fmt.Sprintf("%3d.", first+i)),
+			channelStyle.Render("#"+match.Channel.Name),
+			timeStyle.Render(parseTs(match.Ts).Format("Jan 2 15:04")),
+			usernameStyle.Render(username),
+			highlight(m.redact(match.Text), query),
+		))
+	}
+	out.WriteString("\n" + statusStyle.Render(fmt.Sprintf("%s for the next page, %s for the previous one",
+		searchKeys.NextPage.Help().Key, searchKeys.PrevPage.Help().Key)))
+
+	return out.String()
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+
+// startSelection moves the focus from the input to the messages, selecting
+// the most recent one.
+func (m *model) startSelection() {
+	anyVisible := false
+	for i := range m.messages {
+		if m.visible(i) {
+			anyVisible = true
+			break
+		}
+	}
+	if !anyVisible {
+		m.setStatus("no messages to select")
+		return
+	}
+
+	m.selecting = true
+	m.selected = len(m.messages)
+	m.input.Blur()
+	m.moveSelection(-1)
+}
+
+func (m *model) stopSelection() {
+	m.selecting = false
+	m.input.Focus()
+	m.updateViewportContent()
+}
+
+func (m *model) selectedMessage() (formattedMessage, bool) {
+	if !m.selecting || m.selected < 0 || m.selected >= len(m.messages) {
+		return formattedMessage{}, false
+	}
+
+	return m.messages[m.selected], true
+}
+
+// moveSelection moves the selection by delta visible messages, staying on
+// the first or last one when going past either end. A delta of zero makes
+// sure the selection is on a visible message.
+func (m *model) moveSelection(delta int) {
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+
+	i := m.selected
+	if delta == 0 {
+		if i >= len(m.messages) {
+			i = len(m.messages) - 1
+		}
+		if i >= 0 && m.visible(i) {
+			m.selectionChanged()
+			return
+		}
+		delta = 1
+	}
+
+	for n := delta; n != 0; n -= step {
+		j := i + step
+		for j >= 0 && j < len(m.messages) && !m.visible(j) {
+			j += step
+		}
+		if j < 0 || j >= len(m.messages) {
+			break
+		}
+		i = j
+	}
+
+	// Nothing visible in that direction, try the other one
+	if i < 0 || i >= len(m.messages) || !m.visible(i) {
+		for j := len(m.messages) - 1; j >= 0; j-- {
+			if m.visible(j) {
+				i = j
+				break
+			}
+		}
+	}
+
+	m.selected = i
+	m.selectionChanged()
+}
+
+// selectionChanged updates everything that depends on the selected message.
+func (m *model) selectionChanged() {
+	m.refIndex = -1
+	m.updateReactionPages()
+	m.updateViewportContent()
+}
+
+// scrollToSelection keeps the selected message visible in the viewport.
+func (m *model) scrollToSelection() {
+	if m.selected < 0 || m.selected >= len(m.messageRows) || m.messageRows[m.selected] < 0 {
+		return
+	}
+
+	top := m.messageRows[m.selected]
+	bottom := top + m.messageLines[m.selected] - 1
+
+	if top < m.viewport.YOffset {
+		m.viewport.SetYOffset(top)
+	} else if bottom >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(bottom - m.viewport.Height + 1)
+	}
+}
+
+// handleSelectionKey handles keys while the messages have the focus.
+func (m *model) handleSelectionKey(msg tea.KeyMsg) tea.Cmd {
+	// Deleting needs the key pressed twice in a row, like vim's dd
+	confirmDelete := m.pendingDelete
+	m.pendingDelete = false
+
+	switch {
+	case key.Matches(msg, m.keys.Unselect):
+		// In vim mode this is normal mode, which Esc doesn't leave
+		if !m.config.VimMode {
+			m.stopSelection()
+		}
+	case key.Matches(msg, m.keys.Insert):
+		m.stopSelection()
+	case key.Matches(msg, m.keys.Delete):
+		if confirmDelete {
+			return m.deleteSelected()
+		}
+		m.pendingDelete = true
+	case key.Matches(msg, m.keys.Quit):
+		return tea.Quit
+	case key.Matches(msg, m.keys.SelectUp):
+		m.moveSelection(-1)
+	case key.Matches(msg, m.keys.SelectDown):
+		m.moveSelection(1)
+	case key.Matches(msg, m.keys.SelectFirst):
+		m.moveSelection(-len(m.messages))
+	case key.Matches(msg, m.keys.SelectLast):
+		m.moveSelection(len(m.messages))
+	case key.Matches(msg, m.keys.Save):
+		return m.toggleSaved()
+	case key.Matches(msg, m.keys.OpenSelectedFile):
+		return m.openSelectedFile()
+	case key.Matches(msg, m.keys.CopyCode):
+		return m.copyCodeBlock()
+	case key.Matches(msg, m.keys.ShowProfile):
+		return m.showAuthorProfile()
+	case key.Matches(msg, m.keys.OpenThread):
+		return m.openThread()
+	case key.Matches(msg, m.keys.CopyRaw):
+		return m.copyRaw()
+	case key.Matches(msg, m.keys.React):
+		return m.openEmojiPicker()
+	case key.Matches(msg, m.keys.MarkHandled):
+		m.toggleHandled()
+	case key.Matches(msg, m.keys.LocalPin):
+		m.toggleLocalPin()
+	case key.Matches(msg, m.keys.ToggleExpand):
+		m.toggleExpanded()
+	case key.Matches(msg, m.keys.NextReference):
+		m.nextReference()
+	case key.Matches(msg, m.keys.JumpQuoted):
+		if cmd, ok := m.openReference(); ok {
+			return cmd
+		}
+		return m.jumpToQuoted()
+	case key.Matches(msg, m.keys.ReactionPage):
+		m.nextReactionPage()
+	case key.Matches(msg, m.keys.Help):
+		m.openOverlay("Key bindings", m.keys.helpText())
+	case key.Matches(msg, m.keys.ErrorLog):
+		m.openOverlay("Errors", m.errorLogText())
+	case key.Matches(msg, m.keys.TogglePolling):
+		return m.togglePolling()
+	case key.Matches(msg, m.keys.PageUp):
+		m.viewport.PageUp()
+	case key.Matches(msg, m.keys.PageDown):
+		m.viewport.PageDown()
+	case key.Matches(msg, m.keys.PrevMessage):
+		m.moveSelection(-1)
+	case key.Matches(msg, m.keys.NextMessage):
+		m.moveSelection(1)
+	}
+
+	return nil
+}
+
+func (m *model) openSelectedFile() tea.Cmd {
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	for _, file := range selected.message.Files {
+		if file.IsText() {
+			m.setStatus(fmt.Sprintf("loading %s…", file.Name))
+			return fetchFileContent(m.client, file)
+		}
+	}
+
+	m.setStatus("no text file in this message")
+	return nil
+}
+
+type deleteMessageMsg struct {
+	channelID string
+	ts        string
+	err       error
+}
+
+func (m *model) deleteSelected() tea.Cmd {
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	client, channelID, ts := m.client, m.channelID, selected.id
+	return func() tea.Msg {
+		err := client.DeleteMessage(channelID, ts)
+		return deleteMessageMsg{channelID, ts, err}
+	}
+}
+
+// removeMessage drops a deleted message from the view. Its ID stays known
+// so polling doesn't bring it back.
+func (m *model) removeMessage(ts string) {
+	for i := range m.messages {
+		if m.messages[i].id == ts {
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			break
+		}
+	}
+
+	if len(m.messages) == 0 {
+		m.stopSelection()
+		return
+	}
+	m.moveSelection(0)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snippet is how /snippet shares its content: the language Slack
+// highlights it as and its title.
+type snippet struct {
+	filetype string
+	title    string
+}
+
+type snippetMsg struct {
+	err error
+}
+
+// snippetCommand handles "/snippet [filetype] [title]", uploading the
+// staged message, or what's written in $EDITOR when nothing is staged.
+func (m *model) snippetCommand(args string) tea.Cmd {
+	s := snippet{filetype: "text", title: "snippet"}
+	if filetype, title, _ := strings.Cut(args, " "); filetype != "" {
+		s.filetype = filetype
+		if title = strings.TrimSpace(title); title != "" {
+			s.title = title
+		}
+	}
+
+	if m.staged != "" {
+		content := m.staged
+		m.discardStaged()
+		return m.uploadSnippet(s, content)
+	}
+
+	// The extension lets the editor highlight the language too
+	return m.editInEditor("", "."+s.filetype, editorMsg{snippet: &s})
+}
+
+func (m *model) uploadSnippet(s snippet, content string) tea.Cmd {
+	filename := s.title
+	if !strings.Contains(filename, ".") {
+		filename += "." + s.filetype
+	}
+
+	m.setStatus(fmt.Sprintf("uploading %s…", filename))
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		return snippetMsg{client.UploadSnippet(channelID, content, filename, s.filetype)}
+	}
+}
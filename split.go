@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Channels shown at once in the split view, the current one included
+const (
+	minPanes = 2
+	maxPanes = 4
+)
+
+// enableSplit shows the watched channels in panes along with the current
+// one. The current channel is watched too, so its pane keeps showing its
+// messages once another pane takes the focus.
+func (m *model) enableSplit() error {
+	if m.watchedChannel(m.channelID) == nil {
+		current := &watchedChannel{id: m.channelID, name: m.channelName, channel: m.channel, seen: make(map[string]bool)}
+		m.watched = append([]*watchedChannel{current}, m.watched...)
+	}
+	if n := len(m.watched); n < minPanes || n > maxPanes {
+		return fmt.Errorf("the split view shows %d to %d channels, the current one included, got %d", minPanes, maxPanes, n)
+	}
+
+	m.split = true
+	return nil
+}
+
+// otherPanes returns the channels shown in panes besides the focused one,
+// which is the current channel.
+func (m *model) otherPanes() []*watchedChannel {
+	if !m.split {
+		return nil
+	}
+
+	var others []*watchedChannel
+	for _, w := range m.watched {
+		if w.id != m.channelID {
+			others = append(others, w)
+		}
+	}
+	return others
+}
+
+// paneHeight is the rows each of the other panes takes, title included,
+// sharing the available rows equally with the focused pane. Zero when
+// they don't fit.
+func (m *model) paneHeight(available int) int {
+	others := len(m.otherPanes())
+	if others == 0 {
+		return 0
+	}
+
+	rows := available / (others + 1)
+	if rows < 2 {
+		return 0
+	}
+	return rows
+}
+
+// focusNextPane moves the focus, and so the input, to the next pane by
+// switching to its channel.
+func (m *model) focusNextPane() tea.Cmd {
+	next := m.watched[0]
+	for i, w := range m.watched {
+		if w.id == m.channelID {
+			next = m.watched[(i+1)%len(m.watched)]
+			break
+		}
+	}
+
+	return func() tea.Msg {
+		return channelSwitchMsg{channelID: next.id, channelName: next.name, channel: next.channel}
+	}
+}
+
+// renderPanes renders the panes shown above and below the focused one,
+// keeping every channel in the same place as the focus moves.
+func (m model) renderPanes() (above, below string) {
+	height := m.paneHeight(m.availableHeight(m.windowHeight))
+	if height == 0 {
+		return "", ""
+	}
+
+	beforeFocus := m.watchedChannel(m.channelID) != nil
+	var before, after []string
+	for _, w := range m.watched {
+		switch {
+		case w.id == m.channelID:
+			beforeFocus = false
+		case beforeFocus:
+			before = append(before, m.renderPane(w, height))
+		default:
+			after = append(after, m.renderPane(w, height))
+		}
+	}
+
+	if len(before) > 0 {
+		above = strings.Join(before, "\n") + "\n"
+	}
+	if len(after) > 0 {
+		below = "\n" + strings.Join(after, "\n")
+	}
+	return above, below
+}
+
+// renderPane renders the latest messages of a channel below its name, in
+// exactly height rows.
+func (m model) renderPane(w *watchedChannel, height int) string {
+	label := w.channel.Marker() + w.name
+	if w.unread > 0 {
+		label += fmt.Sprintf(" (%d)", w.unread)
+	}
+	lines := []string{truncate(m.config.channelTheme(w.id, w.name).header.Render(label), m.viewport.Width)}
+
+	// Stored newest first
+	shown := height - 1
+	if shown > len(w.messages) {
+		shown = len(w.messages)
+	}
+	for i := shown - 1; i >= 0; i-- {
+		message := w.messages[i]
+		username, err := m.client.UsernameForMessage(message)
+		if err != nil {
+			username = "unknown"
+		}
+		text := strings.Join(strings.Fields(m.redact(message.Text)), " ")
+		lines = append(lines, truncate(usernameStyle.Render(username)+": "+text, m.viewport.Width))
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSplitView(t *testing.T) {
+	_, unsplit := newTestModel(t)
+	unsplit = update(unsplit, tea.WindowSizeMsg{Width: 80, Height: 24})
+	wantRows := strings.Count(unsplit.View(), "\n") + 1
+
+	_, m := newTestModel(t)
+	m.watched = []*watchedChannel{{id: "C2", name: "alerts", seen: make(map[string]bool)}}
+	if err := m.enableSplit(); err != nil {
+		t.Fatal(err)
+	}
+
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = update(m, fetched(Message{User: "U2", Text: "deploying", Ts: "1700000001.000100"}))
+	m.recordWatched("C2", []Message{{User: "U2", Text: "disk full", Ts: "1700000002.000200"}})
+
+	view := m.View()
+	if !strings.Contains(view, "alerts") || !strings.Contains(view, "bob: disk full") {
+		t.Errorf("the alerts pane isn't shown:\n%s", view)
+	}
+	if rows := strings.Count(view, "\n") + 1; rows != wantRows {
+		t.Errorf("the view takes %d rows, want %d like without panes", rows, wantRows)
+	}
+
+	// Tab moves the focus, and the input, to the alerts pane
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	if cmd == nil {
+		t.Fatal("Tab didn't move the focus")
+	}
+	m = update(m, cmd())
+	if m.channelID != "C2" {
+		t.Fatalf("focused %s, want C2", m.channelID)
+	}
+	if view := m.View(); !strings.Contains(view, "ops") || !strings.Contains(view, "deploying") {
+		t.Errorf("the pane of the channel left isn't shown:\n%s", view)
+	}
+}
+
+func TestSplitViewPanes(t *testing.T) {
+	_, m := newTestModel(t)
+	if err := m.enableSplit(); err == nil {
+		t.Error("split view with a single channel")
+	}
+
+	_, m = newTestModel(t)
+	for _, id := range []string{"C2", "C3", "C4", "C5"} {
+		m.watched = append(m.watched, &watchedChannel{id: id, name: id, seen: make(map[string]bool)})
+	}
+	if err := m.enableSplit(); err == nil {
+		t.Error("split view with 5 channels")
+	}
+}
+
+func TestSplitViewKeepsItalicAndPolling(t *testing.T) {
+	_, m := newTestModel(t)
+	m.watched = []*watchedChannel{{id: "C2", name: "alerts", seen: make(map[string]bool)}}
+	if got := m.watchPollInterval(); got != watchInterval {
+		t.Errorf("watched channels polled every %s, want %s", got, watchInterval)
+	}
+	if err := m.enableSplit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.watchPollInterval(); got != pollInterval {
+		t.Errorf("panes polled every %s, want %s like the focused channel", got, pollInterval)
+	}
+
+	// Tab moves between panes, so italic needs its other key
+	m.input.SetValue("hi")
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i"), Alt: true})
+	if m.input.Value() != "_hi_" {
+		t.Errorf("got input %q after alt+i", m.input.Value())
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ChannelTheme recolors the UI while a channel is open, to tell it apart
+// at a glance, e.g. red for incidents.
+type ChannelTheme struct {
+	// Accent colors the header, the new messages banner and the input
+	// border, as an ANSI color number or a hex code
+	Accent string `json:"accent"`
+
+	// Text is the color of the text on the accent, for contrast
+	Text string `json:"text,omitempty"`
+}
+
+// theme is the set of styles that change with the channel.
+type theme struct {
+	header lipgloss.Style
+	banner lipgloss.Style
+	input  lipgloss.Style
+}
+
+func defaultTheme() theme {
+	return theme{header: channelStyle, banner: unseenStyle, input: inputStyle}
+}
+
+func checkChannelThemes(cfg *Config) error {
+	for channel, t := range cfg.ChannelThemes {
+		if t.Accent == "" {
+			return fmt.Errorf("the theme of %s needs an accent color", channel)
+		}
+	}
+	return nil
+}
+
+// channelTheme returns the styles for a channel, themed by its ID or name
+// (with or without #) in the config, or the default ones.
+func (c *Config) channelTheme(channelID, channelName string) theme {
+	t, ok := c.ChannelThemes[channelID]
+	if !ok {
+		t, ok = c.ChannelThemes["#"+channelName]
+	}
+	if !ok {
+		t, ok = c.ChannelThemes[strings.TrimPrefix(channelName, "#")]
+	}
+	if !ok {
+		return defaultTheme()
+	}
+
+	themed := defaultTheme()
+	accent := lipgloss.Color(t.Accent)
+	themed.header = themed.header.Background(accent)
+	themed.banner = themed.banner.Background(accent)
+	themed.input = themed.input.BorderForeground(accent)
+	if t.Text != "" {
+		text := lipgloss.Color(t.Text)
+		themed.header = themed.header.Foreground(text)
+		themed.banner = themed.banner.Foreground(text)
+	}
+	return themed
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// Messages on screen are refetched this often to catch new thread
+	// replies, which don't show up when polling for new messages
+	threadRefreshInterval = time.Minute
+
+	threadPreviewLength = 60
+)
+
+var threadStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+type threadRefreshMsg struct{}
+
+type threadPreviewMsg struct {
+	ts      string // of the reply
+	preview string
+	err     error
+}
+
+type threadMsg struct {
+	channelID string
+	messages  []Message
+	err       error
+}
+
+func threadRefresh() tea.Cmd {
+	return tea.Tick(threadRefreshInterval, func(time.Time) tea.Msg {
+		return threadRefreshMsg{}
+	})
+}
+
+// refreshThreads refetches the messages on screen, so their reply counts
+// are up to date, including those getting their first reply.
+func (m *model) refreshThreads() tea.Cmd {
+	cmds := []tea.Cmd{threadRefresh()}
+	first, last := m.onScreen()
+	if first < 0 || m.throttled() {
+		return tea.Batch(cmds...)
+	}
+
+	client, channelID := m.client, m.channelID
+	oldest, latest, limit := m.messages[first].message.Ts, m.messages[last].message.Ts, last-first+1
+	cmds = append(cmds, func() tea.Msg {
+		messages, err := client.HistoryBetween(channelID, oldest, latest, limit)
+		return fetchMessagesMsg{channelID, messages, err, client.ThrottledUntil(), false, false}
+	})
+
+	return tea.Batch(cmds...)
+}
+
+// onScreen returns the indexes of the first and last sent messages shown
+// in the viewport, or -1 when there are none.
+func (m *model) onScreen() (first, last int) {
+	first, last = -1, -1
+	top, bottom := m.viewport.YOffset, m.viewport.YOffset+m.viewport.Height
+	for i, row := range m.messageRows {
+		if i >= len(m.messages) || row < 0 || row+m.messageLines[i] <= top || row >= bottom {
+			continue
+		}
+		if m.messages[i].message.Ts == "" {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+
+	return first, last
+}
+
+// updateKnown replaces a message we already have with a newer copy,
+// reporting whether anything changed, e.g. its replies or reactions.
+func (m *model) updateKnown(message Message) bool {
+	for i := range m.messages {
+		if m.messages[i].id != message.Ts {
+			continue
+		}
+		if reflect.DeepEqual(m.messages[i].message, message) {
+			return false
+		}
+		m.messages[i].message = message
+		return true
+	}
+
+	return false
+}
+
+// fetchThreadPreviews looks up the latest reply of the threads we don't
+// have a preview for yet.
+func (m *model) fetchThreadPreviews() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, msg := range m.messages {
+		ts := msg.message.LatestReply
+		if ts == "" {
+			continue
+		}
+		if _, ok := m.threadPreviews[ts]; ok {
+			continue
+		}
+
+		// Empty until it arrives, so it's only fetched once
+		m.threadPreviews[ts] = ""
+
+		client, channelID := m.client, m.channelID
+		cmds = append(cmds, func() tea.Msg {
+			reply, err := client.Message(channelID, ts)
+			if err != nil {
+				return threadPreviewMsg{ts: ts, err: err}
+			}
+
+			username, err := client.UsernameForMessage(*reply)
+			if err != nil {
+				username = "unknown"
+			}
+			return threadPreviewMsg{ts: ts, preview: fmt.Sprintf("%s: %s", username, reply.Text)}
+		})
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// renderThread renders the reply count and latest reply of a message that
+// started a thread.
+func (m *model) renderThread(msg Message) string {
+	if msg.ReplyCount == 0 {
+		return ""
+	}
+
+	noun := "replies"
+	if msg.ReplyCount == 1 {
+		noun = "reply"
+	}
+	line := fmt.Sprintf("🧵 %d %s", msg.ReplyCount, noun)
+
+	if preview := m.threadPreviews[msg.LatestReply]; preview != "" {
+		preview = strings.Join(strings.Fields(m.redact(preview)), " ")
+		if runes := []rune(preview); len(runes) > threadPreviewLength {
+			preview = string(runes[:threadPreviewLength]) + "…"
+		}
+		line += " · " + preview
+	}
+
+	return "\n  " + threadStyle.Render(line)
+}
+
+// openThread shows the thread of the selected message in an overlay.
+func (m *model) openThread() tea.Cmd {
+	selected, ok := m.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	ts := selected.message.ThreadTs
+	if ts == "" {
+		if selected.message.ReplyCount == 0 {
+			m.setStatus("this message has no replies")
+			return nil
+		}
+		ts = selected.message.Ts
+	}
+
+	m.setStatus("loading the thread…")
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		messages, err := client.Replies(channelID, ts)
+		return threadMsg{channelID, messages, err}
+	}
+}
+
+func (m *model) showThread(messages []Message) {
+	rendered := make([]string, 0, len(messages))
+	for _, message := range messages {
+		username, err := m.client.UsernameForMessage(message)
+		if err != nil {
+			username = "unknown"
+		}
+
+		rendered = append(rendered, m.renderMessage(formattedMessage{
+			message:   message,
+			username:  username,
+			timestamp: parseTs(message.Ts),
+			id:        message.Ts,
+		}))
+	}
+
+	m.openOverlay("Thread", strings.Join(rendered, "\n"))
+}
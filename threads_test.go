@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestThreadRefreshOnlyCoversTheScreen(t *testing.T) {
+	_, m := newTestModel(t)
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 10})
+
+	var history []Message
+	for i := 30; i > 0; i-- {
+		history = append(history, Message{User: "U2", Text: "hello", Ts: fmt.Sprintf("1700000000.%06d", i)})
+	}
+	m = update(m, fetched(history...))
+
+	first, last := m.onScreen()
+	if last != len(m.messages)-1 || first == 0 || last-first+1 > m.viewport.Height {
+		t.Errorf("messages %d to %d on screen, want the latest ones", first, last)
+	}
+
+	m.viewport.GotoTop()
+	if first, last := m.onScreen(); first != 0 || last != m.viewport.Height-1 {
+		t.Errorf("messages %d to %d on screen, want the first %d", first, last, m.viewport.Height)
+	}
+
+	// Refetching an older message for its replies
+	parent := m.messages[2].message
+	parent.ReplyCount, parent.LatestReply = 1, "1700000100.000100"
+	m = update(m, fetched(parent))
+
+	if m.messages[2].message.ReplyCount != 1 {
+		t.Error("the reply count wasn't updated")
+	}
+	if m.lastFetched != history[0].Ts {
+		t.Errorf("last fetched moved back to %s", m.lastFetched)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Where message timestamps go
+const (
+	timestampLeft  = "left"
+	timestampRight = "right"
+	timestampNone  = "none"
+)
+
+// timestampShown reports whether timestamps are shown at position.
+func (m *model) timestampShown(position string) bool {
+	return m.showTimestamps && m.config.TimestampPosition == position
+}
+
+// alignTimestamp puts a message's timestamp at the right edge of its first
+// line. It goes right after the text when that's too wide to fit both.
+func (m *model) alignTimestamp(rendered string, msg formattedMessage) string {
+	stamp := timeStyle.Render(msg.timestamp.Format("15:04:05"))
+	first, rest, multiline := strings.Cut(rendered, "\n")
+
+	gap := m.viewport.Width - lipgloss.Width(first) - lipgloss.Width(stamp)
+	if gap < 1 {
+		gap = 1
+	}
+	first += strings.Repeat(" ", gap) + stamp
+
+	if multiline {
+		return first + "\n" + rest
+	}
+	return first
+}
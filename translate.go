@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const translateTimeout = 30 * time.Second
+
+var translationStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
+
+// Translator translates text to the language with the given code, e.g.
+// "en".
+type Translator interface {
+	Translate(text, language string) (string, error)
+}
+
+// TranslateConfig points to a LibreTranslate compatible endpoint, e.g.
+// https://libretranslate.com/translate.
+type TranslateConfig struct {
+	Endpoint string `json:"endpoint"`
+	APIKey   string `json:"api_key,omitempty"`
+	Language string `json:"language"`
+}
+
+type httpTranslator struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func newTranslator(cfg *TranslateConfig) Translator {
+	return &httpTranslator{
+		endpoint: cfg.Endpoint,
+		apiKey:   cfg.APIKey,
+		client:   &http.Client{Timeout: translateTimeout},
+	}
+}
+
+func (t *httpTranslator) Translate(text, language string) (string, error) {
+	request, err := json.Marshal(map[string]string{
+		"q":       text,
+		"source":  "auto",
+		"target":  language,
+		"format":  "text",
+		"api_key": t.apiKey,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(request))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	response := struct {
+		TranslatedText string `json:"translatedText"`
+		Error          string `json:"error"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("translation failed with status %d", resp.StatusCode)
+	}
+	if response.Error != "" {
+		return "", fmt.Errorf("translation failed: %s", response.Error)
+	}
+
+	return response.TranslatedText, nil
+}
+
+type translationMsg struct {
+	ts          string
+	translation string
+	err         error
+}
+
+// translateMessages translates the messages of others we don't have a
+// translation for yet, in the background.
+func (m *model) translateMessages() tea.Cmd {
+	if m.translator == nil {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for _, msg := range m.messages {
+		ts, text := msg.message.Ts, msg.message.Text
+		if ts == "" || strings.TrimSpace(text) == "" || m.ownMessage(msg) {
+			continue
+		}
+		if _, ok := m.translations[ts]; ok {
+			continue
+		}
+
+		// Empty until it arrives, so it's only translated once
+		m.translations[ts] = ""
+
+		translator, language := m.translator, m.config.Translate.Language
+		cmds = append(cmds, func() tea.Msg {
+			translation, err := translator.Translate(text, language)
+			return translationMsg{ts, translation, err}
+		})
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (m *model) addTranslation(msg translationMsg) {
+	if msg.err != nil {
+		// Once is enough, the endpoint is likely down or misconfigured
+		if !m.translateFailed {
+			m.translateFailed = true
+			m.logError(fmt.Errorf("could not translate messages: %w", msg.err))
+		}
+		return
+	}
+
+	m.translations[msg.ts] = msg.translation
+	m.updateViewportContent()
+}
+
+// renderTranslation shows the translation below a message, unless it was
+// already in our language.
+func (m *model) renderTranslation(msg Message) string {
+	translation := strings.TrimSpace(m.translations[msg.Ts])
+	if translation == "" || translation == strings.TrimSpace(msg.Text) {
+		return ""
+	}
+
+	return "\n" + translationStyle.Render("↳ "+m.redact(translation))
+}
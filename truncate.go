@@ -0,0 +1,79 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ansiRe matches an escape sequence at the start of a string, e.g. a color.
+var ansiRe = regexp.MustCompile(`^\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// fitUsername cuts or pads a name to the configured width, so messages
+// line up in a column.
+func (m *model) fitUsername(name string) string {
+	width := m.config.UsernameWidth
+	if width <= 0 {
+		return name
+	}
+
+	name = truncate(name, width)
+	return name + strings.Repeat(" ", width-lipgloss.Width(name))
+}
+
+// truncate shortens s to at most max cells, ending it with an ellipsis
+// when it doesn't fit. Escape sequences don't count towards the width and
+// are kept, and wide characters count as two cells.
+func truncate(s string, max int) string {
+	if lipgloss.Width(s) <= max {
+		return s
+	}
+	if max < 1 {
+		return ""
+	}
+
+	var out strings.Builder
+	width, styled := 0, false
+	for i := 0; i < len(s); {
+		if seq := ansiRe.FindString(s[i:]); seq != "" {
+			out.WriteString(seq)
+			styled = true
+			i += len(seq)
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := lipgloss.Width(string(r))
+		// Leave room for the ellipsis
+		if width+w > max-1 {
+			break
+		}
+		out.WriteRune(r)
+		width += w
+		i += size
+	}
+
+	out.WriteString("…")
+	if styled {
+		// Don't let a cut off style leak into what follows
+		out.WriteString("\x1b[0m")
+	}
+
+	return out.String()
+}
+
+// stripANSI removes the escape sequences from s, leaving what's shown.
+func stripANSI(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		if seq := ansiRe.FindString(s[i:]); seq != "" {
+			i += len(seq)
+			continue
+		}
+		out.WriteByte(s[i])
+		i++
+	}
+	return out.String()
+}
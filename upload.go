@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Shells escape spaces and other special characters with a backslash in
+// dropped paths
+var escapedCharRe = regexp.MustCompile(`\\(.)`)
+
+type uploadFileMsg struct {
+	name string
+	err  error
+}
+
+// droppedFile returns the local file a path dragged or pasted into the
+// input points to. Terminals insert them quoted, with escaped spaces or
+// as file:// URLs.
+func droppedFile(text string) (string, bool) {
+	path := strings.TrimSpace(text)
+	if len(path) >= 2 && (path[0] == '\'' || path[0] == '"') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+	} else {
+		path = escapedCharRe.ReplaceAllString(path, "$1")
+	}
+
+	if rest, ok := strings.CutPrefix(path, "file://"); ok {
+		unescaped, err := url.PathUnescape(rest)
+		if err != nil {
+			return "", false
+		}
+		path = unescaped
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	if !filepath.IsAbs(path) {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+
+	return path, true
+}
+
+// offerUpload asks to confirm uploading a dropped file the first time
+// it's sent, reporting whether it's confirmed.
+func (m *model) offerUpload(path string) bool {
+	if m.uploadPrompt == path {
+		m.uploadPrompt = ""
+		return true
+	}
+
+	m.uploadPrompt = path
+	m.setStatus(fmt.Sprintf("press Enter again to upload %s, or type anything to send the path as text", filepath.Base(path)))
+	return false
+}
+
+// declineUpload remembers that a path offered for upload is meant to be
+// sent as text, once something else is typed.
+func (m *model) declineUpload() {
+	if m.uploadPrompt != "" {
+		m.uploadDeclined = m.uploadPrompt
+		m.uploadPrompt = ""
+	}
+}
+
+func (m *model) uploadFile(path string) tea.Cmd {
+	name := filepath.Base(path)
+	m.setStatus(fmt.Sprintf("uploading %s…", name))
+
+	client, channelID := m.client, m.channelID
+	return func() tea.Msg {
+		return uploadFileMsg{name, client.UploadFile(channelID, path)}
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// Watched channels are polled less often than the current one, unless
+	// they're shown in the split view
+	watchInterval = 30 * time.Second
+
+	// Prefetched messages kept per watched channel
+	maxWatchedMessages = 50
+)
+
+// watchedChannel is a channel polled in the background so its unread
+// count can be shown and switching to it is instant.
+type watchedChannel struct {
+	id       string
+	name     string
+	channel  *Channel  // nil if the channel info isn't available
+	messages []Message // newest first, like the API returns them
+	seen     map[string]bool
+	lastTs   string
+	unread   int
+}
+
+type watchTickMsg struct{}
+
+type watchFetchMsg struct {
+	channelID string
+	messages  []Message
+	err       error
+}
+
+// resolveWatched resolves a comma separated list of channel references.
+func resolveWatched(client *SlackClient, refs string) ([]*watchedChannel, error) {
+	var watched []*watchedChannel
+	for _, ref := range strings.Split(refs, ",") {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+
+		id, err := client.ResolveChannel(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fall back to the ID if the channel info isn't available
+		name := id
+		channel, err := client.ChannelInfo(id)
+		if err == nil {
+			name = client.ChannelDisplayName(channel)
+		} else {
+			channel = nil
+		}
+
+		watched = append(watched, &watchedChannel{id: id, name: name, channel: channel, seen: make(map[string]bool)})
+	}
+
+	return watched, nil
+}
+
+// startWatching fetches the watched channels right away, polling them
+// from then on.
+func (m *model) startWatching() tea.Cmd {
+	if len(m.watched) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		return watchTickMsg{}
+	}
+}
+
+func watchTick(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return watchTickMsg{}
+	})
+}
+
+// watchPollInterval is how often the watched channels are polled, as
+// often as the current one while they're on screen.
+func (m *model) watchPollInterval() time.Duration {
+	if m.split {
+		return pollInterval
+	}
+	return watchInterval
+}
+
+// pollWatched fetches new messages for every watched channel but the
+// current one, which is polled as usual.
+func (m *model) pollWatched() tea.Cmd {
+	cmds := []tea.Cmd{watchTick(m.watchPollInterval())}
+	if m.throttled() {
+		return tea.Batch(cmds...)
+	}
+
+	for _, w := range m.watched {
+		if w.id == m.channelID {
+			continue
+		}
+
+		client, channelID, since, limit := m.client, w.id, w.lastTs, m.config.FetchLimit
+		cmds = append(cmds, func() tea.Msg {
+			history, err := client.History(channelID, since, "", limit)
+			if err != nil {
+				return watchFetchMsg{channelID: channelID, err: err}
+			}
+			return watchFetchMsg{channelID: channelID, messages: history.Messages}
+		})
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (m *model) watchedChannel(channelID string) *watchedChannel {
+	for _, w := range m.watched {
+		if w.id == channelID {
+			return w
+		}
+	}
+	return nil
+}
+
+// recordWatched stores new messages for a watched channel, counting them
+// as unread unless the channel is on screen.
+func (m *model) recordWatched(channelID string, messages []Message) {
+	w := m.watchedChannel(channelID)
+	if w == nil || len(messages) == 0 {
+		return
+	}
+
+	// Messages before the first fetch aren't news
+	first := w.lastTs == ""
+
+	var added []Message
+	unread := 0
+	for _, message := range messages {
+		if w.seen[message.Ts] {
+			continue
+		}
+		w.seen[message.Ts] = true
+		added = append(added, message)
+		if !isFromSelf(message, m.selfID) {
+			unread++
+		}
+	}
+
+	w.messages = append(added, w.messages...)
+	if len(w.messages) > maxWatchedMessages {
+		for _, message := range w.messages[maxWatchedMessages:] {
+			delete(w.seen, message.Ts)
+		}
+		w.messages = w.messages[:maxWatchedMessages]
+	}
+	w.lastTs = messages[0].Ts
+
+	if !first && channelID != m.channelID {
+		w.unread += unread
+	}
+}
+
+// prefetched shows the stored messages of a watched channel straight away
+// after switching to it, while the usual fetch catches up.
+func (m *model) prefetched(channelID string) tea.Cmd {
+	w := m.watchedChannel(channelID)
+	if w == nil {
+		return nil
+	}
+
+	w.unread = 0
+	if len(w.messages) == 0 {
+		return nil
+	}
+
+	messages := append([]Message(nil), w.messages...)
+	client := m.client
+	return func() tea.Msg {
+		return fetchMessagesMsg{channelID, messages, nil, client.ThrottledUntil(), false, false}
+	}
+}
+
+// watchedStatus lists the other watched channels with their unread counts.
+func (m *model) watchedStatus() string {
+	var parts []string
+	for _, w := range m.watched {
+		if w.id == m.channelID {
+			continue
+		}
+
+		part := w.channel.Marker() + w.name
+		if w.unread > 0 {
+			part += fmt.Sprintf(" (%d)", w.unread)
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, " ")
+}